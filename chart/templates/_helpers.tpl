@@ -200,3 +200,30 @@ Return PostgreSQL password secret name
 {{- printf "%s-external-db" (include "docutag.fullname" .) -}}
 {{- end -}}
 {{- end -}}
+
+{{/*
+Pod identity from the downward API, used for per-replica metric labels
+*/}}
+{{- define "docutag.podEnv" -}}
+- name: POD_NAME
+  valueFrom:
+    fieldRef:
+      fieldPath: metadata.name
+- name: POD_NAMESPACE
+  valueFrom:
+    fieldRef:
+      fieldPath: metadata.namespace
+{{- end -}}
+
+{{/*
+Shutdown timeout derived from terminationGracePeriodSeconds, leaving headroom
+for the kubelet to deliver SIGTERM and for endpoints to be removed
+*/}}
+{{- define "docutag.shutdownTimeout" -}}
+{{- $grace := int (default 30 .) -}}
+{{- if gt $grace 10 -}}
+{{- printf "%ds" (sub $grace 10) -}}
+{{- else -}}
+{{- printf "%ds" $grace -}}
+{{- end -}}
+{{- end -}}
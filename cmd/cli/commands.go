@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// runScrape submits each URL to the controller and prints the resulting requests
+func runScrape(ctx context.Context, app *App, args []string) error {
+	fs := app.newFlagSet("scrape")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("at least one URL is required")
+	}
+
+	var records []map[string]interface{}
+	for _, target := range fs.Args() {
+		var record map[string]interface{}
+		if err := app.client.Do(ctx, "POST", "/api/scrape", map[string]interface{}{"url": target}, &record); err != nil {
+			return fmt.Errorf("failed to scrape %s: %w", target, err)
+		}
+		records = append(records, record)
+	}
+
+	return app.printRecords(records, requestColumns)
+}
+
+// runAnalyze submits text for analysis; text comes from args, -f or stdin
+func runAnalyze(ctx context.Context, app *App, args []string) error {
+	fs := app.newFlagSet("analyze")
+	file := fs.String("f", "", "read text from file (- for stdin)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	text, err := app.readText(*file, fs.Args())
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(text) == "" {
+		return errors.New("no text to analyze")
+	}
+
+	var record map[string]interface{}
+	if err := app.client.Do(ctx, "POST", "/api/analyze", map[string]interface{}{"text": text}, &record); err != nil {
+		return fmt.Errorf("failed to analyze text: %w", err)
+	}
+
+	return app.printRecords([]map[string]interface{}{record}, requestColumns)
+}
+
+// readText returns analysis input from a file, stdin or the remaining arguments
+func (app *App) readText(file string, args []string) (string, error) {
+	switch {
+	case file == "-" || (file == "" && len(args) == 0):
+		data, err := io.ReadAll(app.stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return string(data), nil
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		return string(data), nil
+	default:
+		return strings.Join(args, " "), nil
+	}
+}
+
+// runSearch finds requests by tag and prints the matching requests
+func runSearch(ctx context.Context, app *App, args []string) error {
+	fs := app.newFlagSet("search")
+	exact := fs.Bool("exact", false, "disable fuzzy tag matching")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("at least one tag is required")
+	}
+
+	var result struct {
+		RequestIDs []string `json:"request_ids"`
+		Count      int      `json:"count"`
+	}
+	body := map[string]interface{}{"tags": fs.Args(), "fuzzy": !*exact}
+	if err := app.client.Do(ctx, "POST", "/api/search", body, &result); err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+
+	records := make([]map[string]interface{}, 0, len(result.RequestIDs))
+	for _, id := range result.RequestIDs {
+		var record map[string]interface{}
+		if err := app.client.Do(ctx, "GET", "/api/requests/"+url.PathEscape(id), nil, &record); err != nil {
+			return fmt.Errorf("failed to get request %s: %w", id, err)
+		}
+		records = append(records, record)
+	}
+
+	return app.printRecords(records, requestColumns)
+}
+
+// runExport pages through all requests and writes them as NDJSON or a JSON array
+func runExport(ctx context.Context, app *App, args []string) error {
+	fs := app.newFlagSet("export")
+	pageSize := fs.Int("page-size", 100, "requests fetched per API call")
+	format := fs.String("format", "ndjson", "export format: ndjson or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *pageSize <= 0 {
+		return errors.New("page-size must be positive")
+	}
+	if *format != "ndjson" && *format != "json" {
+		return fmt.Errorf("invalid export format %q (want ndjson or json)", *format)
+	}
+
+	var all []map[string]interface{}
+	enc := json.NewEncoder(app.stdout)
+	offset, cursor := 0, ""
+	for {
+		page, next, err := app.listRequests(ctx, *pageSize, offset, cursor)
+		if err != nil {
+			return err
+		}
+
+		if *format == "ndjson" {
+			for _, record := range page {
+				if err := enc.Encode(record); err != nil {
+					return fmt.Errorf("failed to write record: %w", err)
+				}
+			}
+		} else {
+			all = append(all, page...)
+		}
+
+		// Follow next_cursor when the controller returns one; fall back to offsets otherwise.
+		// Only an empty page ends an offset export, since the controller may cap the limit
+		// below -page-size and return short pages before the end.
+		if next != "" {
+			cursor = next
+			continue
+		}
+		if cursor != "" || len(page) == 0 {
+			break
+		}
+		offset += len(page)
+	}
+
+	if *format == "json" {
+		if all == nil {
+			all = []map[string]interface{}{}
+		}
+		return app.printJSON(all)
+	}
+	return nil
+}
+
+// listRequests fetches one page of requests from the controller, by cursor if one is
+// given and by offset otherwise. Returns the next page's cursor, if the controller sent one.
+func (app *App) listRequests(ctx context.Context, limit, offset int, cursor string) ([]map[string]interface{}, string, error) {
+	var result struct {
+		Requests   []map[string]interface{} `json:"requests"`
+		NextCursor string                   `json:"next_cursor"`
+	}
+	path := fmt.Sprintf("/api/requests?limit=%d&offset=%d", limit, offset)
+	if cursor != "" {
+		path = fmt.Sprintf("/api/requests?limit=%d&cursor=%s", limit, url.QueryEscape(cursor))
+	}
+	if err := app.client.Do(ctx, "GET", path, nil, &result); err != nil {
+		return nil, "", fmt.Errorf("failed to list requests: %w", err)
+	}
+	return result.Requests, result.NextCursor, nil
+}
+
+// runRequeue resubmits the URLs of the given (or all failed) scrape requests
+func runRequeue(ctx context.Context, app *App, args []string) error {
+	fs := app.newFlagSet("requeue")
+	failed := fs.Bool("failed", false, "requeue every scrape request with status failed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*failed && fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("scrape request IDs or -failed is required")
+	}
+
+	var targets []map[string]interface{}
+	if *failed {
+		scrapeRequests, err := app.listScrapeRequests(ctx)
+		if err != nil {
+			return err
+		}
+		for _, sr := range scrapeRequests {
+			if sr["status"] == "failed" {
+				targets = append(targets, sr)
+			}
+		}
+	}
+	for _, id := range fs.Args() {
+		var sr map[string]interface{}
+		if err := app.client.Do(ctx, "GET", "/api/scrape-requests/"+url.PathEscape(id), nil, &sr); err != nil {
+			return fmt.Errorf("failed to get scrape request %s: %w", id, err)
+		}
+		targets = append(targets, sr)
+	}
+
+	var records []map[string]interface{}
+	for _, sr := range targets {
+		target := scrapeRequestURL(sr)
+		if target == "" {
+			fmt.Fprintf(app.stderr, "skipping %v: no URL to requeue\n", sr["id"])
+			continue
+		}
+
+		var record map[string]interface{}
+		if err := app.client.Do(ctx, "POST", "/api/scrape", map[string]interface{}{"url": target}, &record); err != nil {
+			return fmt.Errorf("failed to requeue %s: %w", target, err)
+		}
+		records = append(records, record)
+	}
+
+	return app.printRecords(records, requestColumns)
+}
+
+// listScrapeRequests fetches all async scrape requests from the controller
+func (app *App) listScrapeRequests(ctx context.Context) ([]map[string]interface{}, error) {
+	var result struct {
+		Requests []map[string]interface{} `json:"requests"`
+	}
+	if err := app.client.Do(ctx, "GET", "/api/scrape-requests", nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to list scrape requests: %w", err)
+	}
+	return result.Requests, nil
+}
+
+// scrapeRequestURL returns the source URL of a scrape request record
+func scrapeRequestURL(sr map[string]interface{}) string {
+	for _, key := range []string{"url", "source_url"} {
+		if value, ok := sr[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// runTombstone tombstones each request ID
+func runTombstone(ctx context.Context, app *App, args []string) error {
+	fs := app.newFlagSet("tombstone")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("at least one request ID is required")
+	}
+
+	records := make([]map[string]interface{}, 0, fs.NArg())
+	for _, id := range fs.Args() {
+		if err := app.client.Do(ctx, "PUT", "/api/requests/"+url.PathEscape(id)+"/tombstone", nil, nil); err != nil {
+			return fmt.Errorf("failed to tombstone %s: %w", id, err)
+		}
+		records = append(records, map[string]interface{}{"id": id, "status": "tombstoned"})
+	}
+
+	return app.printRecords(records, []column{field("ID", "id"), field("STATUS", "status")})
+}
+
+// runStatus prints controller health and scrape request counts by status
+func runStatus(ctx context.Context, app *App, args []string) error {
+	fs := app.newFlagSet("status")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{"controller": "healthy"}
+	keys := []string{"controller"}
+
+	var health map[string]interface{}
+	if err := app.client.Do(ctx, "GET", "/health", nil, &health); err != nil {
+		values["controller"] = "unhealthy: " + err.Error()
+	} else if status, ok := health["status"].(string); ok && status != "" {
+		values["controller"] = status
+	}
+
+	scrapeRequests, err := app.listScrapeRequests(ctx)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, sr := range scrapeRequests {
+		status, _ := sr["status"].(string)
+		if status == "" {
+			status = "unknown"
+		}
+		counts[status]++
+	}
+
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	values["scrape_requests"] = len(scrapeRequests)
+	keys = append(keys, "scrape_requests")
+	for _, status := range statuses {
+		key := "scrape_requests_" + status
+		values[key] = counts[status]
+		keys = append(keys, key)
+	}
+
+	return app.printKeyValues(keys, values)
+}
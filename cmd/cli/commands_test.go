@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newTestController returns a fake controller API serving a fixed set of requests
+func newTestController(t *testing.T, total int) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var calls []string
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/requests", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		requests := []map[string]interface{}{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			requests = append(requests, map[string]interface{}{"id": fmt.Sprintf("req-%d", i), "source_type": "url"})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"requests": requests})
+	})
+
+	mux.HandleFunc("/api/scrape-requests", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		json.NewEncoder(w).Encode(map[string]interface{}{"requests": []map[string]interface{}{
+			{"id": "sr-1", "status": "failed", "url": "https://example.com/a"},
+			{"id": "sr-2", "status": "completed", "url": "https://example.com/b"},
+			{"id": "sr-3", "status": "failed", "url": "https://example.com/c"},
+		}})
+	})
+
+	mux.HandleFunc("/api/scrape", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+body["url"])
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "new", "source_url": body["url"]})
+	})
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// runCLI invokes the CLI against a test server and returns stdout, stderr and the exit code
+func runCLI(server *httptest.Server, args ...string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"-url", server.URL, "-api-key", "secret"}, args...)
+	code := run(args, strings.NewReader(""), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestExportPaginates(t *testing.T) {
+	server, calls := newTestController(t, 5)
+
+	stdout, stderr, code := runCLI(server, "export", "-page-size", "2")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 NDJSON lines, got %d: %q", len(lines), stdout)
+	}
+
+	// 5 records at page size 2 means pages of 2, 2 and 1, then an empty page ends the export
+	if len(*calls) != 4 {
+		t.Errorf("Expected 4 list calls, got %d: %v", len(*calls), *calls)
+	}
+}
+
+func TestExportWithCappedLimit(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.RequestURI())
+		// The server caps limit at 2 whatever the client asks for
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		requests := []map[string]interface{}{}
+		for i := offset; i < 5 && i < offset+2; i++ {
+			requests = append(requests, map[string]interface{}{"id": fmt.Sprintf("req-%d", i)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"requests": requests})
+	}))
+	defer server.Close()
+
+	stdout, stderr, code := runCLI(server, "export", "-page-size", "100")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(stdout), "\n"); len(lines) != 5 {
+		t.Errorf("Expected all 5 records despite the capped limit, got %d: %q", len(lines), stdout)
+	}
+	if len(calls) != 4 || calls[1] != "/api/requests?limit=100&offset=2" {
+		t.Errorf("Expected offsets to advance by page length, got %v", calls)
+	}
+}
+
+func TestExportFollowsCursor(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.RequestURI())
+		pages := map[string]map[string]interface{}{
+			"":   {"requests": []map[string]string{{"id": "a"}, {"id": "b"}}, "next_cursor": "c1"},
+			"c1": {"requests": []map[string]string{{"id": "c"}, {"id": "d"}}, "next_cursor": "c2"},
+			"c2": {"requests": []map[string]string{{"id": "e"}}},
+		}
+		json.NewEncoder(w).Encode(pages[r.URL.Query().Get("cursor")])
+	}))
+	defer server.Close()
+
+	stdout, stderr, code := runCLI(server, "export", "-page-size", "2")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(stdout), "\n"); len(lines) != 5 {
+		t.Errorf("Expected 5 NDJSON lines, got %d: %q", len(lines), stdout)
+	}
+
+	want := []string{
+		"/api/requests?limit=2&offset=0",
+		"/api/requests?limit=2&cursor=c1",
+		"/api/requests?limit=2&cursor=c2",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected calls:\n got: %v\nwant: %v", calls, want)
+	}
+}
+
+func TestRequeueFailed(t *testing.T) {
+	server, calls := newTestController(t, 0)
+
+	_, stderr, code := runCLI(server, "-o", "json", "requeue", "-failed")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+
+	want := []string{
+		"GET /api/scrape-requests",
+		"POST /api/scrape https://example.com/a",
+		"POST /api/scrape https://example.com/c",
+	}
+	if strings.Join(*calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected calls:\n got: %v\nwant: %v", *calls, want)
+	}
+}
+
+func TestStatusCountsByStatus(t *testing.T) {
+	server, _ := newTestController(t, 0)
+
+	stdout, stderr, code := runCLI(server, "-o", "json", "status")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+
+	var status map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &status); err != nil {
+		t.Fatalf("Failed to decode status output: %v", err)
+	}
+	if status["controller"] != "healthy" {
+		t.Errorf("Expected healthy controller, got %v", status["controller"])
+	}
+	if status["scrape_requests_failed"] != float64(2) {
+		t.Errorf("Expected 2 failed scrape requests, got %v", status["scrape_requests_failed"])
+	}
+}
+
+func TestAPIErrorExitCode(t *testing.T) {
+	server, _ := newTestController(t, 1)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-url", server.URL, "export"}, strings.NewReader(""), &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("Expected exit code 1 without API key, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "status 401") {
+		t.Errorf("Expected 401 in error output, got %q", stderr.String())
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	server, _ := newTestController(t, 0)
+
+	_, stderr, code := runCLI(server, "frobnicate")
+	if code != 2 {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr, "unknown command") {
+		t.Errorf("Expected unknown command message, got %q", stderr)
+	}
+}
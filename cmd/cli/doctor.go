@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// doctorCheck is the outcome of one doctor check
+type doctorCheck struct {
+	Name   string
+	Status string // ok, fail or skip
+	Detail string
+}
+
+// doctorColumns are the table columns for doctor checks
+var doctorColumns = []column{
+	field("CHECK", "name"),
+	field("STATUS", "status"),
+	field("DETAIL", "detail"),
+}
+
+// unhealthyWords mark a component status reported by a /health endpoint as failing
+var unhealthyWords = []string{"error", "fail", "unhealthy", "degraded", "disconnected", "unavailable", "down"}
+
+// checkService checks a service's /health endpoint and any component statuses it reports
+// (e.g. database, redis, migrations, storage)
+func checkService(ctx context.Context, name string, client *Client) []doctorCheck {
+	if client == nil {
+		return []doctorCheck{{Name: name, Status: "skip", Detail: "no URL configured"}}
+	}
+
+	var health map[string]interface{}
+	if err := client.Do(ctx, "GET", "/health", nil, &health); err != nil {
+		return []doctorCheck{{Name: name, Status: "fail", Detail: err.Error()}}
+	}
+
+	check := doctorCheck{Name: name, Status: "ok", Detail: "reachable"}
+	if status, ok := health["status"].(string); ok && status != "" {
+		check.Detail = status
+		if isUnhealthy(status) {
+			check.Status = "fail"
+		}
+	}
+	if version, ok := health["version"].(string); ok && version != "" {
+		check.Detail += ", version " + version
+	}
+	checks := []doctorCheck{check}
+
+	components := make([]string, 0, len(health))
+	for key := range health {
+		if key != "status" && key != "version" {
+			components = append(components, key)
+		}
+	}
+	sort.Strings(components)
+
+	for _, key := range components {
+		value, ok := componentStatus(health[key])
+		if !ok {
+			continue
+		}
+		component := doctorCheck{Name: name + "/" + key, Status: "ok", Detail: value}
+		if isUnhealthy(value) {
+			component.Status = "fail"
+		}
+		checks = append(checks, component)
+	}
+	return checks
+}
+
+// componentStatus extracts a status string from a health field, which is either
+// a plain string or an object with a status field
+func componentStatus(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case map[string]interface{}:
+		status, ok := val["status"].(string)
+		if !ok {
+			return "", false
+		}
+		if msg, ok := val["error"].(string); ok && msg != "" {
+			status += ": " + msg
+		}
+		return status, true
+	}
+	return "", false
+}
+
+func isUnhealthy(status string) bool {
+	status = strings.ToLower(status)
+	for _, word := range unhealthyWords {
+		if strings.Contains(status, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOllama checks the Ollama server version and that each required model has been pulled
+func checkOllama(ctx context.Context, client *Client, models []string) []doctorCheck {
+	if client == nil {
+		return []doctorCheck{{Name: "ollama", Status: "skip", Detail: "no URL configured"}}
+	}
+
+	var version struct {
+		Version string `json:"version"`
+	}
+	if err := client.Do(ctx, "GET", "/api/version", nil, &version); err != nil {
+		return []doctorCheck{{Name: "ollama", Status: "fail", Detail: err.Error()}}
+	}
+	checks := []doctorCheck{{Name: "ollama", Status: "ok", Detail: "version " + version.Version}}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := client.Do(ctx, "GET", "/api/tags", nil, &tags); err != nil {
+		return append(checks, doctorCheck{Name: "ollama/models", Status: "fail", Detail: err.Error()})
+	}
+
+	available := make(map[string]bool, len(tags.Models))
+	for _, m := range tags.Models {
+		available[m.Name] = true
+	}
+
+	for _, model := range models {
+		check := doctorCheck{Name: "ollama/model " + model, Status: "ok", Detail: "present"}
+		if !hasModel(available, model) {
+			check.Status = "fail"
+			check.Detail = "missing, run: ollama pull " + model
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// hasModel reports whether a model is available; an untagged name matches its :latest tag
+func hasModel(available map[string]bool, model string) bool {
+	if available[model] {
+		return true
+	}
+	return !strings.Contains(model, ":") && available[model+":latest"]
+}
+
+// runDoctor checks connectivity to every pipeline dependency and prints a report.
+// Returns an error (exit code 1) if any check fails.
+func runDoctor(ctx context.Context, app *App, args []string) error {
+	fs := app.newFlagSet("doctor")
+	scraperURL := fs.String("scraper-url", getEnv("PURPLEPILL_SCRAPER_URL", "http://localhost:9081"), "scraper base URL, empty to skip (env PURPLEPILL_SCRAPER_URL)")
+	textanalyzerURL := fs.String("textanalyzer-url", getEnv("PURPLEPILL_TEXTANALYZER_URL", "http://localhost:9082"), "textanalyzer base URL, empty to skip (env PURPLEPILL_TEXTANALYZER_URL)")
+	schedulerURL := fs.String("scheduler-url", getEnv("PURPLEPILL_SCHEDULER_URL", "http://localhost:9083"), "scheduler base URL, empty to skip (env PURPLEPILL_SCHEDULER_URL)")
+	ollamaURL := fs.String("ollama-url", getEnv("OLLAMA_URL", "http://localhost:11434"), "Ollama base URL, empty to skip (env OLLAMA_URL)")
+	models := fs.String("models", getEnv("OLLAMA_MODEL", "gemma3:4b"), "comma-separated Ollama models that must be pulled (env OLLAMA_MODEL)")
+	timeout := fs.Duration("check-timeout", 10*time.Second, "timeout for each check")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	optionalClient := func(url string) *Client {
+		if url == "" {
+			return nil
+		}
+		return NewClient(url, "", *timeout)
+	}
+
+	var required []string
+	for _, m := range strings.Split(*models, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			required = append(required, m)
+		}
+	}
+
+	var checks []doctorCheck
+	checks = append(checks, checkService(ctx, "controller", app.client)...)
+	checks = append(checks, checkService(ctx, "scraper", optionalClient(*scraperURL))...)
+	checks = append(checks, checkService(ctx, "textanalyzer", optionalClient(*textanalyzerURL))...)
+	checks = append(checks, checkService(ctx, "scheduler", optionalClient(*schedulerURL))...)
+	checks = append(checks, checkOllama(ctx, optionalClient(*ollamaURL), required)...)
+
+	failed := 0
+	records := make([]map[string]interface{}, len(checks))
+	for i, c := range checks {
+		records[i] = map[string]interface{}{"name": c.Name, "status": c.Status, "detail": c.Detail}
+		if c.Status == "fail" {
+			failed++
+		}
+	}
+
+	if err := app.printRecords(records, doctorColumns); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestOllama returns a fake Ollama server with the given models pulled
+func newTestOllama(t *testing.T, models ...string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/version", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"version": "0.6.2"})
+	})
+	mux.HandleFunc("/api/tags", func(w http.ResponseWriter, r *http.Request) {
+		list := []map[string]string{}
+		for _, m := range models {
+			list = append(list, map[string]string{"name": m})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"models": list})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestHealth returns a fake service whose /health responds with body
+func newTestHealth(t *testing.T, body map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDoctorAllHealthy(t *testing.T) {
+	controller, _ := newTestController(t, 0)
+	scraper := newTestHealth(t, map[string]interface{}{"status": "healthy", "database": "connected"})
+	ollama := newTestOllama(t, "gemma3:4b", "llava:latest")
+
+	stdout, stderr, code := runCLI(controller, "doctor",
+		"-scraper-url", scraper.URL, "-textanalyzer-url", "", "-scheduler-url", "",
+		"-ollama-url", ollama.URL, "-models", "gemma3:4b,llava")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s\n%s", code, stderr, stdout)
+	}
+
+	for _, want := range []string{"scraper/database", "connected", "textanalyzer", "skip", "version 0.6.2", "ollama/model llava"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "fail") {
+		t.Errorf("Expected no failed checks, got:\n%s", stdout)
+	}
+}
+
+func TestDoctorReportsFailures(t *testing.T) {
+	controller, _ := newTestController(t, 0)
+	scraper := newTestHealth(t, map[string]interface{}{
+		"status":   "degraded",
+		"database": map[string]interface{}{"status": "unhealthy", "error": "connection refused"},
+	})
+	ollama := newTestOllama(t)
+
+	stdout, stderr, code := runCLI(controller, "-o", "json", "doctor",
+		"-scraper-url", scraper.URL, "-textanalyzer-url", "", "-scheduler-url", "",
+		"-ollama-url", ollama.URL, "-models", "gemma3:4b")
+	if code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr, "3 of 7 checks failed") {
+		t.Errorf("Expected failure summary, got %q", stderr)
+	}
+
+	var checks []map[string]string
+	if err := json.Unmarshal([]byte(stdout), &checks); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v\n%s", err, stdout)
+	}
+
+	statuses := map[string]string{}
+	for _, c := range checks {
+		statuses[c["name"]] = c["status"]
+	}
+	if statuses["scraper"] != "fail" {
+		t.Errorf("Expected degraded scraper check to fail, got %v", statuses)
+	}
+	if statuses["scraper/database"] != "fail" {
+		t.Errorf("Expected scraper database check to fail, got %v", statuses)
+	}
+	if statuses["ollama/model gemma3:4b"] != "fail" {
+		t.Errorf("Expected missing model check to fail, got %v", statuses)
+	}
+	if statuses["controller"] != "ok" {
+		t.Errorf("Expected controller check to pass, got %v", statuses)
+	}
+}
@@ -0,0 +1,145 @@
+// Command purplepill is an operator CLI for the DocuTag pipeline.
+// It talks to the controller API so operators don't have to craft curl commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+)
+
+// App holds state shared by all subcommands
+type App struct {
+	client *Client
+	output string // table or json
+	stdout io.Writer
+	stderr io.Writer
+	stdin  io.Reader
+}
+
+// command is a CLI subcommand
+type command struct {
+	summary string
+	usage   string
+	run     func(ctx context.Context, app *App, args []string) error
+}
+
+// commands maps subcommand names to their implementation; populated in init
+// because subcommands look up their own usage text
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"scrape":    {summary: "Scrape and analyze one or more URLs", usage: "scrape <url>...", run: runScrape},
+		"analyze":   {summary: "Analyze text from arguments, a file or stdin", usage: "analyze [-f file] [text...]", run: runAnalyze},
+		"search":    {summary: "Search documents by tag", usage: "search [-exact] <tag>...", run: runSearch},
+		"export":    {summary: "Export all requests as NDJSON or a JSON array", usage: "export [-page-size n] [-format ndjson|json]", run: runExport},
+		"requeue":   {summary: "Resubmit failed scrape requests", usage: "requeue [-failed] [scrape-request-id...]", run: runRequeue},
+		"tombstone": {summary: "Tombstone one or more requests", usage: "tombstone <request-id>...", run: runTombstone},
+		"status":    {summary: "Show controller health and scrape request counts", usage: "status", run: runStatus},
+		"doctor":    {summary: "Check connectivity to services, Ollama and required models", usage: "doctor [-models m1,m2] [-ollama-url url]", run: runDoctor},
+		"top":       {summary: "Live dashboard of queue depth, jobs, failures and Ollama latency", usage: "top [-interval d] [-failures n]", run: runTop},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run parses global flags, dispatches to a subcommand and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("purplepill", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	baseURL := fs.String("url", getEnv("PURPLEPILL_URL", "http://localhost:9080"), "controller base URL (env PURPLEPILL_URL)")
+	apiKey := fs.String("api-key", os.Getenv("PURPLEPILL_API_KEY"), "API key sent as a bearer token (env PURPLEPILL_API_KEY)")
+	output := fs.String("o", "table", "output format: table or json")
+	timeout := fs.Duration("timeout", 5*time.Minute, "per-request timeout")
+	fs.Usage = func() { printUsage(fs, stderr) }
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(stderr, "invalid output format %q (want table or json)\n", *output)
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		printUsage(fs, stderr)
+		return 2
+	}
+
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", name)
+		printUsage(fs, stderr)
+		return 2
+	}
+
+	app := &App{
+		client: NewClient(*baseURL, *apiKey, *timeout),
+		output: *output,
+		stdout: stdout,
+		stderr: stderr,
+		stdin:  stdin,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(ctx, app, fs.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "purplepill %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// printUsage prints global usage with the list of subcommands
+func printUsage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "Usage: purplepill [flags] <command> [command flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	fs.PrintDefaults()
+}
+
+// newFlagSet creates a flag set for a subcommand that reports errors to the app's stderr
+func (app *App) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(app.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(app.stderr, "Usage: purplepill %s\n", commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// getEnv returns an environment variable or a default value
+func getEnv(key, defaultVal string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultVal
+}
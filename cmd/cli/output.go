@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// column describes one table column and how to extract it from a record
+type column struct {
+	header string
+	value  func(record map[string]interface{}) string
+}
+
+// field returns a column that renders a top-level record field
+func field(header, key string) column {
+	return column{
+		header: header,
+		value: func(record map[string]interface{}) string {
+			return formatValue(record[key])
+		},
+	}
+}
+
+// requestColumns are the table columns for controller request records
+var requestColumns = []column{
+	field("ID", "id"),
+	field("TYPE", "source_type"),
+	field("SOURCE", "source_url"),
+	field("TAGS", "tags"),
+	field("CREATED", "created_at"),
+}
+
+// printRecords writes records as a table or as JSON, depending on the output flag
+func (app *App) printRecords(records []map[string]interface{}, columns []column) error {
+	if app.output == "json" {
+		return app.printJSON(records)
+	}
+
+	tw := tabwriter.NewWriter(app.stdout, 0, 4, 2, ' ', 0)
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.header
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, record := range records {
+		values := make([]string, len(columns))
+		for i, col := range columns {
+			values[i] = col.value(record)
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printKeyValues writes ordered key/value pairs as a two-column table or a JSON object
+func (app *App) printKeyValues(keys []string, values map[string]interface{}) error {
+	if app.output == "json" {
+		return app.printJSON(values)
+	}
+
+	tw := tabwriter.NewWriter(app.stdout, 0, 4, 2, ' ', 0)
+	for _, key := range keys {
+		fmt.Fprintf(tw, "%s\t%s\n", key, formatValue(values[key]))
+	}
+	return tw.Flush()
+}
+
+// printJSON writes v as indented JSON
+func (app *App) printJSON(v interface{}) error {
+	enc := json.NewEncoder(app.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// formatValue renders a JSON value for a table cell
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		if val == "" {
+			return "-"
+		}
+		return val
+	case float64:
+		return fmt.Sprintf("%g", val)
+	case []interface{}:
+		parts := make([]string, 0, len(val))
+		for _, item := range val {
+			parts = append(parts, formatValue(item))
+		}
+		return strings.Join(parts, ",")
+	default:
+		data, _ := json.Marshal(val)
+		return string(data)
+	}
+}
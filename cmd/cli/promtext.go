@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+// sample is a single Prometheus sample from the text exposition format
+type sample struct {
+	labels map[string]string
+	value  float64
+}
+
+// metricSet maps metric names to their samples
+type metricSet map[string][]sample
+
+// parseMetrics parses the Prometheus text exposition format, skipping comments and malformed lines
+func parseMetrics(text string) metricSet {
+	set := make(metricSet)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, labels, rest := line, map[string]string{}, ""
+		if open := strings.IndexByte(line, '{'); open >= 0 {
+			end := strings.LastIndexByte(line, '}')
+			if end < open {
+				continue
+			}
+			name = line[:open]
+			labels = parseLabels(line[open+1 : end])
+			rest = line[end+1:]
+		} else if space := strings.IndexByte(line, ' '); space >= 0 {
+			name = line[:space]
+			rest = line[space:]
+		}
+
+		// Value may be followed by a timestamp
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+
+		set[name] = append(set[name], sample{labels: labels, value: value})
+	}
+
+	return set
+}
+
+// parseLabels parses a label list like `a="x",b="y"`
+func parseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 || eq+1 >= len(s) || s[eq+1] != '"' {
+			break
+		}
+		key := strings.TrimSpace(s[:eq])
+
+		// Scan the quoted value, honouring escapes
+		var value strings.Builder
+		i := eq + 2
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				if s[i] == 'n' {
+					value.WriteByte('\n')
+					continue
+				}
+			}
+			value.WriteByte(s[i])
+		}
+		labels[key] = value.String()
+
+		s = strings.TrimPrefix(strings.TrimSpace(s[min(i+1, len(s)):]), ",")
+	}
+	return labels
+}
+
+// sum returns the sum of all samples of a metric whose labels include the given pairs
+func (m metricSet) sum(name string, match map[string]string) (float64, bool) {
+	total, found := 0.0, false
+	for _, s := range m[name] {
+		if labelsMatch(s.labels, match) {
+			total += s.value
+			found = true
+		}
+	}
+	return total, found
+}
+
+// byLabel sums a metric's samples grouped by the value of one label
+func (m metricSet) byLabel(name, label string) map[string]float64 {
+	grouped := make(map[string]float64)
+	for _, s := range m[name] {
+		grouped[s.labels[label]] += s.value
+	}
+	return grouped
+}
+
+// labelsMatch reports whether labels contain every pair in match
+func labelsMatch(labels, match map[string]string) bool {
+	for k, v := range match {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
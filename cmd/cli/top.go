@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dashboardSources are the endpoints the dashboard polls
+type dashboardSources struct {
+	controller   *Client
+	scraper      *Client
+	textanalyzer *Client
+}
+
+// latencySample holds a histogram's cumulative sum and count, so rates can be
+// computed between two polls
+type latencySample struct {
+	sum   float64
+	count float64
+	ok    bool
+}
+
+// dashboardSnapshot is one poll of the pipeline's state
+type dashboardSnapshot struct {
+	fetchedAt        time.Time
+	controllerHealth string
+	queueLength      float64
+	hasQueueLength   bool
+	jobsByStatus     map[string]float64
+	failures         []map[string]interface{}
+	scrapeLatency    latencySample
+	analysisLatency  latencySample
+	ollamaRequests   map[string]float64
+	errors           []string
+}
+
+// collectSnapshot polls the controller API and each service's /metrics endpoint.
+// Failures of individual sources are recorded in the snapshot rather than aborting the poll.
+func collectSnapshot(ctx context.Context, src dashboardSources, failureLimit int) dashboardSnapshot {
+	snap := dashboardSnapshot{
+		fetchedAt:      time.Now(),
+		jobsByStatus:   make(map[string]float64),
+		ollamaRequests: make(map[string]float64),
+	}
+
+	var health map[string]interface{}
+	if err := src.controller.Do(ctx, "GET", "/health", nil, &health); err != nil {
+		snap.controllerHealth = "unreachable"
+		snap.errors = append(snap.errors, fmt.Sprintf("controller health: %v", err))
+	} else if status, ok := health["status"].(string); ok && status != "" {
+		snap.controllerHealth = status
+	} else {
+		snap.controllerHealth = "healthy"
+	}
+
+	if text, err := src.controller.GetText(ctx, "/metrics"); err != nil {
+		snap.errors = append(snap.errors, fmt.Sprintf("controller metrics: %v", err))
+	} else {
+		m := parseMetrics(text)
+		snap.queueLength, snap.hasQueueLength = m.sum("docutab_queue_length", nil)
+		snap.jobsByStatus = m.byLabel("docutab_scrape_jobs_by_status", "status")
+	}
+
+	var list struct {
+		Requests []map[string]interface{} `json:"requests"`
+	}
+	if err := src.controller.Do(ctx, "GET", "/api/scrape-requests", nil, &list); err != nil {
+		snap.errors = append(snap.errors, fmt.Sprintf("scrape requests: %v", err))
+	} else {
+		snap.failures = recentFailures(list.Requests, failureLimit)
+	}
+
+	if src.scraper != nil {
+		if text, err := src.scraper.GetText(ctx, "/metrics"); err != nil {
+			snap.errors = append(snap.errors, fmt.Sprintf("scraper metrics: %v", err))
+		} else {
+			m := parseMetrics(text)
+			snap.scrapeLatency = histogramSample(m, "docutab_scrape_duration_seconds")
+			for status, value := range m.byLabel("docutab_ollama_requests_total", "status") {
+				snap.ollamaRequests[status] += value
+			}
+		}
+	}
+
+	if src.textanalyzer != nil {
+		if text, err := src.textanalyzer.GetText(ctx, "/metrics"); err != nil {
+			snap.errors = append(snap.errors, fmt.Sprintf("textanalyzer metrics: %v", err))
+		} else {
+			m := parseMetrics(text)
+			snap.analysisLatency = histogramSample(m, "docutab_analysis_duration_seconds")
+			for status, value := range m.byLabel("docutab_analyzer_ollama_requests_total", "status") {
+				snap.ollamaRequests[status] += value
+			}
+		}
+	}
+
+	return snap
+}
+
+// histogramSample extracts a histogram's cumulative sum and count
+func histogramSample(m metricSet, name string) latencySample {
+	sum, okSum := m.sum(name+"_sum", nil)
+	count, okCount := m.sum(name+"_count", nil)
+	return latencySample{sum: sum, count: count, ok: okSum && okCount}
+}
+
+// recentFailures returns the most recent failed scrape requests, newest first
+func recentFailures(requests []map[string]interface{}, limit int) []map[string]interface{} {
+	var failed []map[string]interface{}
+	for _, r := range requests {
+		if r["status"] == "failed" {
+			failed = append(failed, r)
+		}
+	}
+
+	// RFC 3339 timestamps sort lexically
+	sort.SliceStable(failed, func(i, j int) bool {
+		a, _ := failed[i]["created_at"].(string)
+		b, _ := failed[j]["created_at"].(string)
+		return a > b
+	})
+
+	if len(failed) > limit {
+		failed = failed[:limit]
+	}
+	return failed
+}
+
+// meanLatency returns the mean latency over the interval between two samples,
+// falling back to the lifetime mean when no new observations were recorded
+func meanLatency(prev, cur latencySample) (float64, bool) {
+	if !cur.ok || cur.count == 0 {
+		return 0, false
+	}
+	if prev.ok && cur.count > prev.count && cur.sum >= prev.sum {
+		return (cur.sum - prev.sum) / (cur.count - prev.count), true
+	}
+	return cur.sum / cur.count, true
+}
+
+// snapshotMsg delivers a completed poll to the model
+type snapshotMsg dashboardSnapshot
+
+// tickMsg triggers the next poll
+type tickMsg time.Time
+
+// dashboardModel is the bubbletea model for the top command
+type dashboardModel struct {
+	ctx      context.Context
+	sources  dashboardSources
+	interval time.Duration
+	limit    int
+	current  *dashboardSnapshot
+	previous *dashboardSnapshot
+	polling  bool
+}
+
+func (m dashboardModel) poll() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, m.interval+5*time.Second)
+		defer cancel()
+		return snapshotMsg(collectSnapshot(ctx, m.sources, m.limit))
+	}
+}
+
+func (m dashboardModel) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// Init starts the first poll
+func (m dashboardModel) Init() tea.Cmd {
+	return m.poll()
+}
+
+// Update handles key presses, ticks and poll results
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "r":
+			if !m.polling {
+				m.polling = true
+				return m, m.poll()
+			}
+		}
+	case tickMsg:
+		if !m.polling {
+			m.polling = true
+			return m, m.poll()
+		}
+	case snapshotMsg:
+		snap := dashboardSnapshot(msg)
+		m.previous, m.current = m.current, &snap
+		m.polling = false
+		return m, m.tick()
+	}
+	return m, nil
+}
+
+// View renders the dashboard
+func (m dashboardModel) View() string {
+	if m.current == nil {
+		return "Connecting to controller...\n\nq: quit\n"
+	}
+	return renderDashboard(m.current, m.previous, m.interval)
+}
+
+// renderDashboard formats a snapshot as plain text; prev may be nil
+func renderDashboard(cur, prev *dashboardSnapshot, interval time.Duration) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "DocuTag pipeline  ·  controller %s  ·  updated %s (every %s)\n\n",
+		cur.controllerHealth, cur.fetchedAt.Format("15:04:05"), interval)
+
+	b.WriteString("QUEUE\n")
+	if cur.hasQueueLength {
+		fmt.Fprintf(&b, "  depth         %.0f", cur.queueLength)
+		if prev != nil && prev.hasQueueLength {
+			fmt.Fprintf(&b, " (%+.0f)", cur.queueLength-prev.queueLength)
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("  depth         -\n")
+	}
+
+	b.WriteString("\nJOBS BY STATUS\n")
+	if len(cur.jobsByStatus) == 0 {
+		b.WriteString("  -\n")
+	}
+	statuses := make([]string, 0, len(cur.jobsByStatus))
+	for status := range cur.jobsByStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		fmt.Fprintf(&b, "  %-13s %.0f\n", status, cur.jobsByStatus[status])
+	}
+
+	b.WriteString("\nPIPELINE LATENCY\n")
+	var prevScrape, prevAnalysis latencySample
+	if prev != nil {
+		prevScrape, prevAnalysis = prev.scrapeLatency, prev.analysisLatency
+	}
+	fmt.Fprintf(&b, "  scrape        %s\n", formatLatency(meanLatency(prevScrape, cur.scrapeLatency)))
+	fmt.Fprintf(&b, "  analysis      %s\n", formatLatency(meanLatency(prevAnalysis, cur.analysisLatency)))
+	b.WriteString("\nOLLAMA\n")
+	if len(cur.ollamaRequests) == 0 {
+		b.WriteString("  requests      -\n")
+	} else {
+		keys := make([]string, 0, len(cur.ollamaRequests))
+		for status := range cur.ollamaRequests {
+			keys = append(keys, status)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, status := range keys {
+			parts = append(parts, fmt.Sprintf("%s=%.0f", status, cur.ollamaRequests[status]))
+		}
+		fmt.Fprintf(&b, "  requests      %s\n", strings.Join(parts, " "))
+	}
+
+	b.WriteString("\nRECENT FAILURES\n")
+	if len(cur.failures) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, f := range cur.failures {
+		fmt.Fprintf(&b, "  %-20s %-40s %s\n",
+			truncate(formatValue(f["created_at"]), 20),
+			truncate(formatValue(scrapeRequestURL(f)), 40),
+			truncate(formatValue(f["error_message"]), 60))
+	}
+
+	if len(cur.errors) > 0 {
+		b.WriteString("\nERRORS\n")
+		for _, e := range cur.errors {
+			fmt.Fprintf(&b, "  %s\n", truncate(e, 100))
+		}
+	}
+
+	b.WriteString("\nr: refresh  q: quit\n")
+	return b.String()
+}
+
+// formatLatency renders a mean latency or a dash when unavailable
+func formatLatency(seconds float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Millisecond).String()
+}
+
+// truncate shortens s to n runes with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// runTop runs the interactive dashboard until the user quits
+func runTop(ctx context.Context, app *App, args []string) error {
+	fs := app.newFlagSet("top")
+	interval := fs.Duration("interval", 2*time.Second, "refresh interval")
+	scraperURL := fs.String("scraper-url", getEnv("PURPLEPILL_SCRAPER_URL", "http://localhost:9081"), "scraper base URL for Ollama metrics, empty to disable (env PURPLEPILL_SCRAPER_URL)")
+	textanalyzerURL := fs.String("textanalyzer-url", getEnv("PURPLEPILL_TEXTANALYZER_URL", "http://localhost:9082"), "textanalyzer base URL for Ollama metrics, empty to disable (env PURPLEPILL_TEXTANALYZER_URL)")
+	failures := fs.Int("failures", 5, "number of recent failures to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	src := dashboardSources{controller: app.client}
+	if *scraperURL != "" {
+		src.scraper = NewClient(*scraperURL, "", 10*time.Second)
+	}
+	if *textanalyzerURL != "" {
+		src.textanalyzer = NewClient(*textanalyzerURL, "", 10*time.Second)
+	}
+
+	model := dashboardModel{ctx: ctx, sources: src, interval: *interval, limit: *failures}
+	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(ctx))
+	if _, err := program.Run(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("dashboard failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleMetrics = `# HELP docutab_queue_length Current number of jobs in the queue
+# TYPE docutab_queue_length gauge
+docutab_queue_length 7
+docutab_scrape_jobs_by_status{status="pending"} 4
+docutab_scrape_jobs_by_status{status="failed"} 2
+docutab_scrape_duration_seconds_sum{status="success"} 12.5
+docutab_scrape_duration_seconds_sum{status="error"} 2.5
+docutab_scrape_duration_seconds_count{status="success"} 5
+docutab_scrape_duration_seconds_count{status="error"} 1
+docutab_ollama_requests_total{type="extract",status="success"} 9 1700000000000
+weird_metric{label="a \"quoted\", value"} 1
+`
+
+func TestParseMetrics(t *testing.T) {
+	m := parseMetrics(sampleMetrics)
+
+	if queue, ok := m.sum("docutab_queue_length", nil); !ok || queue != 7 {
+		t.Errorf("Expected queue length 7, got %v (found=%v)", queue, ok)
+	}
+
+	jobs := m.byLabel("docutab_scrape_jobs_by_status", "status")
+	if jobs["pending"] != 4 || jobs["failed"] != 2 {
+		t.Errorf("Unexpected jobs by status: %v", jobs)
+	}
+
+	// Timestamp after the value must be ignored
+	if v, _ := m.sum("docutab_ollama_requests_total", map[string]string{"status": "success"}); v != 9 {
+		t.Errorf("Expected 9 successful Ollama requests, got %v", v)
+	}
+
+	if got := m["weird_metric"][0].labels["label"]; got != `a "quoted", value` {
+		t.Errorf("Expected escaped label value to be unquoted, got %q", got)
+	}
+
+	latency := histogramSample(m, "docutab_scrape_duration_seconds")
+	if !latency.ok || latency.sum != 15 || latency.count != 6 {
+		t.Errorf("Unexpected histogram sample: %+v", latency)
+	}
+}
+
+func TestMeanLatency(t *testing.T) {
+	tests := []struct {
+		name string
+		prev latencySample
+		cur  latencySample
+		want float64
+		ok   bool
+	}{
+		{"no data", latencySample{}, latencySample{}, 0, false},
+		{"first poll uses lifetime mean", latencySample{}, latencySample{sum: 10, count: 4, ok: true}, 2.5, true},
+		{"interval mean", latencySample{sum: 10, count: 4, ok: true}, latencySample{sum: 16, count: 6, ok: true}, 3, true},
+		{"no new observations", latencySample{sum: 10, count: 4, ok: true}, latencySample{sum: 10, count: 4, ok: true}, 2.5, true},
+		{"counter reset", latencySample{sum: 10, count: 4, ok: true}, latencySample{sum: 1, count: 1, ok: true}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := meanLatency(tt.prev, tt.cur)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("meanLatency() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRenderDashboard(t *testing.T) {
+	requests := []map[string]interface{}{
+		{"id": "1", "status": "failed", "url": "https://old.example", "created_at": "2025-01-01T00:00:00Z", "error_message": "timeout"},
+		{"id": "2", "status": "completed", "url": "https://ok.example", "created_at": "2025-01-03T00:00:00Z"},
+		{"id": "3", "status": "failed", "url": "https://new.example", "created_at": "2025-01-02T00:00:00Z", "error_message": "ollama unavailable"},
+	}
+
+	failures := recentFailures(requests, 5)
+	if len(failures) != 2 || failures[0]["id"] != "3" {
+		t.Fatalf("Expected 2 failures newest first, got %v", failures)
+	}
+
+	snap := &dashboardSnapshot{
+		fetchedAt:        time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC),
+		controllerHealth: "healthy",
+		queueLength:      3,
+		hasQueueLength:   true,
+		jobsByStatus:     map[string]float64{"pending": 3},
+		failures:         failures,
+		scrapeLatency:    latencySample{sum: 3, count: 2, ok: true},
+		ollamaRequests:   map[string]float64{"success": 10, "error": 1},
+	}
+
+	view := renderDashboard(snap, nil, 2*time.Second)
+	for _, want := range []string{"controller healthy", "depth         3", "pending", "PIPELINE LATENCY\n  scrape        1.5s", "OLLAMA\n  requests      error=1 success=10", "https://new.example", "ollama unavailable"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected dashboard to contain %q:\n%s", want, view)
+		}
+	}
+}
@@ -15,4 +15,13 @@ psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" <<-E
     GRANT ALL PRIVILEGES ON DATABASE scheduler_db TO docutag;
 EOSQL
 
+# Enable pg_stat_statements in each service database so services can read
+# their own statement stats (the library is preloaded via dev.conf/staging.conf).
+# $POSTGRES_DB is included for docker-compose.single-db.yml, where every service
+# uses that database, and for postgres-exporter, which connects to it.
+for db in "$POSTGRES_DB" controller_db textanalyzer_db scraper_db scheduler_db; do
+    psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$db" \
+        -c "CREATE EXTENSION IF NOT EXISTS pg_stat_statements;"
+done
+
 echo "All service databases created successfully"
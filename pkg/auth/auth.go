@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/docutag/platform/pkg/metrics"
+)
+
+// ErrKeyNotFound is returned by a KeyStore when no active key matches
+var ErrKeyNotFound = errors.New("api key not found")
+
+// keyPrefix marks generated secrets so they are easy to spot in logs and secret scanners
+const keyPrefix = "pp_"
+
+// Key identifies the client behind a request. The secret itself is never kept.
+type Key struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// KeyStore looks up keys by the SHA-256 hash of their secret (see HashKey).
+// The controller implements it over its database for key management.
+type KeyStore interface {
+	LookupKey(ctx context.Context, hash string) (*Key, error)
+}
+
+// HashKey returns the hex SHA-256 of a secret, which is what stores persist
+func HashKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateKey returns a new random secret. Only its hash should be stored;
+// the secret is shown to the caller once.
+func GenerateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// StaticKeyStore is a fixed set of keys, used for bootstrap keys from the environment
+type StaticKeyStore map[string]*Key
+
+// LookupKey implements KeyStore
+func (s StaticKeyStore) LookupKey(ctx context.Context, hash string) (*Key, error) {
+	if key, ok := s[hash]; ok {
+		return key, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+// ParseStaticKeys parses a comma-separated list of id:secret pairs, as set in API_KEYS
+func ParseStaticKeys(value string) (StaticKeyStore, error) {
+	store := StaticKeyStore{}
+	for i, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// Never echo the entry: without a colon it is likely a bare secret
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("invalid API_KEYS entry at position %d: want id:secret", i+1)
+		}
+		store[HashKey(secret)] = &Key{ID: id, Name: id}
+	}
+	return store, nil
+}
+
+// StaticKeysFromEnv loads bootstrap keys from API_KEYS. Returns a nil KeyStore if none are set,
+// so passing the result straight to Config.Store leaves authentication disabled.
+func StaticKeysFromEnv() (KeyStore, error) {
+	store, err := ParseStaticKeys(os.Getenv("API_KEYS"))
+	if err != nil || len(store) == 0 {
+		return nil, err
+	}
+	return store, nil
+}
+
+// MultiKeyStore checks each store in order, e.g. bootstrap keys then database keys
+type MultiKeyStore []KeyStore
+
+// LookupKey implements KeyStore
+func (m MultiKeyStore) LookupKey(ctx context.Context, hash string) (*Key, error) {
+	for _, store := range m {
+		key, err := store.LookupKey(ctx, hash)
+		if err == nil {
+			return key, nil
+		}
+		if !errors.Is(err, ErrKeyNotFound) {
+			return nil, err
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+type contextKey struct{}
+
+// KeyFromContext returns the authenticated key for a request, if any
+func KeyFromContext(ctx context.Context) (*Key, bool) {
+	key, ok := ctx.Value(contextKey{}).(*Key)
+	return key, ok
+}
+
+// Config configures the authentication middleware
+type Config struct {
+	// Store validates keys; a nil store disables authentication
+	Store KeyStore
+	// PublicPaths are path prefixes served without a key (health checks, metrics, SEO content)
+	PublicPaths []string
+	// Metrics records per-key usage; optional
+	Metrics *metrics.APIKeyMetrics
+}
+
+// DefaultPublicPaths are the endpoints that must stay reachable without a key
+var DefaultPublicPaths = []string{"/health", "/ready", "/metrics", "/content/", "/robots.txt", "/sitemap", "/images-sitemap.xml"}
+
+// Middleware requires a valid API key in the Authorization header
+// ("Bearer <key>") on every request outside the public paths
+func Middleware(cfg Config) func(http.Handler) http.Handler {
+	if cfg.Store == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions || isPublic(r.URL.Path, cfg.PublicPaths) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			secret, ok := bearerToken(r)
+			if !ok {
+				reject(w, cfg.Metrics, "missing", "missing API key")
+				return
+			}
+
+			key, err := cfg.Store.LookupKey(r.Context(), HashKey(secret))
+			if errors.Is(err, ErrKeyNotFound) {
+				reject(w, cfg.Metrics, "invalid", "invalid API key")
+				return
+			}
+			if err != nil {
+				http.Error(w, `{"error":"failed to validate API key"}`, http.StatusInternalServerError)
+				return
+			}
+
+			wrapped := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(wrapped, r.WithContext(context.WithValue(r.Context(), contextKey{}, key)))
+
+			if cfg.Metrics != nil {
+				cfg.Metrics.ObserveRequest(key.ID, wrapped.status)
+			}
+		})
+	}
+}
+
+func isPublic(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func reject(w http.ResponseWriter, m *metrics.APIKeyMetrics, reason, message string) {
+	if m != nil {
+		m.ObserveAuthFailure(reason)
+	}
+	w.Header().Set("WWW-Authenticate", `Bearer realm="docutag"`)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	fmt.Fprintf(w, `{"error":%q}`, message)
+}
+
+// statusWriter captures the response status for per-key metrics
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
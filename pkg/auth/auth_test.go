@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docutag/platform/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestHandler(t *testing.T, cfg Config) http.Handler {
+	t.Helper()
+	return Middleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key, ok := KeyFromContext(r.Context()); ok {
+			w.Header().Set("X-Key-ID", key.ID)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestParseStaticKeys(t *testing.T) {
+	store, err := ParseStaticKeys("ingest:s3cret, admin:other")
+	if err != nil {
+		t.Fatalf("ParseStaticKeys() error: %v", err)
+	}
+
+	key, err := store.LookupKey(context.Background(), HashKey("s3cret"))
+	if err != nil || key.ID != "ingest" {
+		t.Errorf("Expected ingest key, got %+v, %v", key, err)
+	}
+	if _, err := store.LookupKey(context.Background(), HashKey("ingest")); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound for key ID used as secret, got %v", err)
+	}
+
+	_, err = ParseStaticKeys("ingest:s3cret, mysecretvalue")
+	if err == nil {
+		t.Fatal("Expected error for entry without id")
+	}
+	if strings.Contains(err.Error(), "mysecretvalue") || !strings.Contains(err.Error(), "position 2") {
+		t.Errorf("Expected error to name the position without echoing the entry, got %q", err)
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	a, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error: %v", err)
+	}
+	b, _ := GenerateKey()
+
+	if !strings.HasPrefix(a, keyPrefix) || len(a) < 40 {
+		t.Errorf("Unexpected key format %q", a)
+	}
+	if a == b {
+		t.Error("Expected distinct keys")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	m := metrics.NewAPIKeyMetrics("controller")
+	store, _ := ParseStaticKeys("ingest:s3cret")
+	handler := newTestHandler(t, Config{Store: store, PublicPaths: DefaultPublicPaths, Metrics: m})
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+		keyID         string
+	}{
+		{"valid key", "/api/scrape", "Bearer s3cret", http.StatusOK, "ingest"},
+		{"lowercase scheme", "/api/scrape", "bearer s3cret", http.StatusOK, "ingest"},
+		{"missing key", "/api/scrape", "", http.StatusUnauthorized, ""},
+		{"invalid key", "/api/scrape", "Bearer wrong", http.StatusUnauthorized, ""},
+		{"basic auth", "/api/scrape", "Basic czNjcmV0", http.StatusUnauthorized, ""},
+		{"public health", "/health", "", http.StatusOK, ""},
+		{"public content", "/content/some-slug", "", http.StatusOK, ""},
+		{"public sitemap", "/sitemap.xml", "", http.StatusOK, ""},
+		{"public image sitemap", "/images-sitemap.xml", "", http.StatusOK, ""},
+		{"non-API path", "/admin", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if got := w.Header().Get("X-Key-ID"); got != tt.keyID {
+				t.Errorf("Expected key ID %q in context, got %q", tt.keyID, got)
+			}
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected WWW-Authenticate header on 401")
+			}
+		})
+	}
+
+	if v := testutil.ToFloat64(m.RequestsTotal.WithLabelValues("ingest", "200")); v != 2 {
+		t.Errorf("Expected 2 requests for ingest key, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.AuthFailuresTotal.WithLabelValues("missing")); v != 3 {
+		t.Errorf("Expected 3 missing-key failures, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.AuthFailuresTotal.WithLabelValues("invalid")); v != 1 {
+		t.Errorf("Expected 1 invalid-key failure, got %v", v)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) LookupKey(ctx context.Context, hash string) (*Key, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func TestMultiKeyStore(t *testing.T) {
+	static, _ := ParseStaticKeys("bootstrap:first")
+	store := MultiKeyStore{static, failingStore{}}
+
+	if key, err := store.LookupKey(context.Background(), HashKey("first")); err != nil || key.ID != "bootstrap" {
+		t.Errorf("Expected bootstrap key, got %+v, %v", key, err)
+	}
+
+	// Store errors other than not-found surface as 500s rather than 401s
+	handler := newTestHandler(t, Config{Store: store})
+	req := httptest.NewRequest("GET", "/api/requests", nil)
+	req.Header.Set("Authorization", "Bearer other")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func TestMiddlewareDisabled(t *testing.T) {
+	handler := newTestHandler(t, Config{})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/scrape", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 with auth disabled, got %d", w.Code)
+	}
+}
+
+func TestMiddlewareDisabledWithoutEnvKeys(t *testing.T) {
+	for _, value := range []string{"", " , "} {
+		t.Setenv("API_KEYS", value)
+
+		store, err := StaticKeysFromEnv()
+		if err != nil {
+			t.Fatalf("StaticKeysFromEnv() error: %v", err)
+		}
+		handler := newTestHandler(t, Config{Store: store})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/scrape", nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200 with API_KEYS=%q, got %d", value, w.Code)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package cors
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Origin is an allowed origin and its credentials policy
+type Origin struct {
+	Origin           string
+	AllowCredentials bool
+}
+
+// Config holds the CORS policy shared by all frontends talking to a service
+type Config struct {
+	Origins        []Origin
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	MaxAge         time.Duration
+}
+
+// DefaultConfig returns a config with no allowed origins and the methods and
+// headers the DocuTag frontends use, exposing the idempotent replay and rate limit
+// response headers to them
+func DefaultConfig() Config {
+	return Config{
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "Idempotency-Key"},
+		ExposedHeaders: []string{"Idempotent-Replayed", "Retry-After"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// ConfigFromEnv builds a config from CORS_ORIGINS and CORS_CREDENTIALS_ORIGINS.
+// Both are comma-separated origin lists; origins in CORS_CREDENTIALS_ORIGINS are
+// allowed and may send cookies/auth headers. Empty lists leave CORS disabled.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	cfg.Origins = ParseOrigins(os.Getenv("CORS_ORIGINS"), os.Getenv("CORS_CREDENTIALS_ORIGINS"))
+	return cfg
+}
+
+// ParseOrigins merges a list of allowed origins with a list of origins that
+// are additionally allowed credentials. "*" allows any origin, but never with credentials.
+func ParseOrigins(allowed, withCredentials string) []Origin {
+	var origins []Origin
+	index := map[string]int{}
+
+	add := func(list string, credentials bool) {
+		for _, o := range strings.Split(list, ",") {
+			o = strings.TrimRight(strings.TrimSpace(o), "/")
+			if o == "" {
+				continue
+			}
+			if i, ok := index[o]; ok {
+				origins[i].AllowCredentials = origins[i].AllowCredentials || credentials
+				continue
+			}
+			index[o] = len(origins)
+			origins = append(origins, Origin{Origin: o, AllowCredentials: credentials})
+		}
+	}
+	add(allowed, false)
+	add(withCredentials, true)
+
+	return origins
+}
+
+// Middleware applies the CORS policy and answers preflight requests.
+// With no configured origins it passes every request straight through.
+func Middleware(cfg Config) func(http.Handler) http.Handler {
+	if len(cfg.Origins) == 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	exact := make(map[string]Origin, len(cfg.Origins))
+	var wildcard bool
+	for _, o := range cfg.Origins {
+		if o.Origin == "*" {
+			wildcard = true
+			continue
+		}
+		exact[o.Origin] = o
+	}
+
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			// Responses differ by origin, so caches must key on it
+			w.Header().Add("Vary", "Origin")
+
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			policy, ok := exact[origin]
+			switch {
+			case ok:
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if policy.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			case wildcard:
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			default:
+				// Disallowed origin: no CORS headers, so the browser blocks the response
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if preflight {
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if exposed != "" {
+				w.Header().Set("Access-Control-Expose-Headers", exposed)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseOrigins(t *testing.T) {
+	origins := ParseOrigins(
+		"https://docutag.app, https://admin.docutag.app/,",
+		"https://admin.docutag.app,https://staging-admin.docutag.app",
+	)
+
+	expected := []Origin{
+		{Origin: "https://docutag.app"},
+		{Origin: "https://admin.docutag.app", AllowCredentials: true},
+		{Origin: "https://staging-admin.docutag.app", AllowCredentials: true},
+	}
+	if !reflect.DeepEqual(origins, expected) {
+		t.Errorf("ParseOrigins() = %+v, expected %+v", origins, expected)
+	}
+
+	if origins := ParseOrigins("", ""); origins != nil {
+		t.Errorf("Expected no origins, got %+v", origins)
+	}
+}
+
+func newTestHandler(cfg Config) http.Handler {
+	return Middleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestMiddlewarePerOriginCredentials(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Origins = ParseOrigins("https://docutag.app", "https://admin.docutag.app")
+	handler := newTestHandler(cfg)
+
+	tests := []struct {
+		origin      string
+		allowOrigin string
+		credentials string
+	}{
+		{"https://docutag.app", "https://docutag.app", ""},
+		{"https://admin.docutag.app", "https://admin.docutag.app", "true"},
+		{"https://evil.example.com", "", ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/requests", nil)
+		req.Header.Set("Origin", tt.origin)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d", tt.origin, w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, expected %q", tt.origin, got, tt.allowOrigin)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.credentials {
+			t.Errorf("%s: Access-Control-Allow-Credentials = %q, expected %q", tt.origin, got, tt.credentials)
+		}
+		if got := w.Header().Get("Vary"); got != "Origin" {
+			t.Errorf("%s: expected Vary: Origin, got %q", tt.origin, got)
+		}
+	}
+}
+
+func TestMiddlewarePreflight(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Origins = ParseOrigins("https://docutag.app", "")
+	handler := newTestHandler(cfg)
+
+	req := httptest.NewRequest("OPTIONS", "/api/scrape", nil)
+	req.Header.Set("Origin", "https://docutag.app")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got == "" {
+		t.Error("Expected Access-Control-Allow-Methods to be set")
+	}
+	if got := w.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type, Authorization, Idempotency-Key" {
+		t.Errorf("Expected allowed headers %q, got %q", "Content-Type, Authorization, Idempotency-Key", got)
+	}
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("Expected max age 600, got %q", got)
+	}
+
+	// Preflight from a disallowed origin is answered without CORS headers
+	req.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected no Access-Control-Allow-Origin, got %q", got)
+	}
+}
+
+func TestMiddlewareWildcardNeverAllowsCredentials(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Origins = ParseOrigins("*", "*")
+	handler := newTestHandler(cfg)
+
+	req := httptest.NewRequest("GET", "/content/abc", nil)
+	req.Header.Set("Origin", "https://anywhere.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected wildcard origin, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Expected no credentials with wildcard, got %q", got)
+	}
+}
+
+func TestMiddlewareExposedHeaders(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Origins = ParseOrigins("https://docutag.app", "")
+	handler := newTestHandler(cfg)
+
+	req := httptest.NewRequest("POST", "/api/scrape", nil)
+	req.Header.Set("Origin", "https://docutag.app")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "Idempotent-Replayed, Retry-After" {
+		t.Errorf("Expected exposed headers %q, got %q", "Idempotent-Replayed, Retry-After", got)
+	}
+
+	// Disallowed origins get no CORS headers at all
+	req.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "" {
+		t.Errorf("Expected no exposed headers for a disallowed origin, got %q", got)
+	}
+}
+
+func TestMiddlewareDisabled(t *testing.T) {
+	t.Setenv("CORS_ORIGINS", "")
+	t.Setenv("CORS_CREDENTIALS_ORIGINS", "")
+	handler := newTestHandler(ConfigFromEnv())
+
+	req := httptest.NewRequest("GET", "/api/requests", nil)
+	req.Header.Set("Origin", "https://docutag.app")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if len(w.Header()) != 0 {
+		t.Errorf("Expected no headers when CORS is disabled, got %v", w.Header())
+	}
+}
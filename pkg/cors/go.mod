@@ -0,0 +1,3 @@
+module github.com/docutag/platform/pkg/cors
+
+go 1.24.0
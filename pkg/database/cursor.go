@@ -0,0 +1,45 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Cursor is a keyset pagination position for listings ordered by (created_at DESC, id DESC).
+// Unlike OFFSET, seeking to a cursor uses the (created_at, id) index however deep the page.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"i"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string for next_cursor fields
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by Encode
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if c.CreatedAt.IsZero() || c.ID == "" {
+		return c, fmt.Errorf("invalid cursor: missing position")
+	}
+	return c, nil
+}
+
+// Where returns a condition selecting rows after the cursor, with placeholders
+// starting at $argIndex, and its arguments. Pair it with
+// ORDER BY createdAtCol DESC, idCol DESC and an index on (createdAtCol, idCol).
+func (c Cursor) Where(createdAtCol, idCol string, argIndex int) (string, []interface{}) {
+	cond := fmt.Sprintf("(%s, %s) < ($%d, $%d)", createdAtCol, idCol, argIndex, argIndex+1)
+	return cond, []interface{}{c.CreatedAt, c.ID}
+}
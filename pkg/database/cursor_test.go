@@ -0,0 +1,56 @@
+package database
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{CreatedAt: time.Date(2025, 10, 1, 12, 30, 0, 123456000, time.UTC), ID: "0b9f3c5e-8f7d-4a1b-9c2d-3e4f5a6b7c8d"}
+
+	encoded := c.Encode()
+	if _, err := base64.RawURLEncoding.DecodeString(encoded); err != nil {
+		t.Errorf("Expected URL-safe unpadded base64, got %q: %v", encoded, err)
+	}
+
+	decoded, err := DecodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error: %v", err)
+	}
+	if !decoded.CreatedAt.Equal(c.CreatedAt) || decoded.ID != c.ID {
+		t.Errorf("Round trip mismatch: got %+v, want %+v", decoded, c)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":           "",
+		"not base64":      "not a cursor!",
+		"not JSON":        base64.RawURLEncoding.EncodeToString([]byte("hello")),
+		"missing ID":      base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2025-10-01T12:30:00Z"}`)),
+		"missing time":    base64.RawURLEncoding.EncodeToString([]byte(`{"i":"abc"}`)),
+		"wrong time type": base64.RawURLEncoding.EncodeToString([]byte(`{"t":1,"i":"abc"}`)),
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeCursor(input); err == nil {
+				t.Errorf("Expected error for %q", input)
+			}
+		})
+	}
+}
+
+func TestCursorWhere(t *testing.T) {
+	c := Cursor{CreatedAt: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), ID: "abc"}
+
+	cond, args := c.Where("r.created_at", "r.id", 3)
+	if cond != "(r.created_at, r.id) < ($3, $4)" {
+		t.Errorf("Unexpected condition: %s", cond)
+	}
+	if !reflect.DeepEqual(args, []interface{}{c.CreatedAt, "abc"}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// LeaderElector elects a single leader among replicas using a PostgreSQL
+// session-level advisory lock. The lock is tied to one connection, so it is
+// released automatically if the leader's pod dies or loses its connection.
+type LeaderElector struct {
+	db       *sql.DB
+	name     string
+	key      int64
+	interval time.Duration
+	leader   atomic.Bool
+}
+
+// NewLeaderElector creates a leader elector for a named singleton task (e.g. "sitemap-regen").
+// Replicas using the same name compete for the same lock.
+func NewLeaderElector(db *sql.DB, name string) *LeaderElector {
+	h := fnv.New64a()
+	h.Write([]byte("docutab:" + name))
+
+	return &LeaderElector{
+		db:       db,
+		name:     name,
+		key:      int64(h.Sum64()),
+		interval: getEnvAsDuration("LEADER_ELECTION_INTERVAL", 15*time.Second),
+	}
+}
+
+// IsLeader reports whether this replica currently holds the lock
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run campaigns for leadership until ctx is cancelled. While this replica is
+// leader, fn runs with a context that is cancelled as soon as leadership is lost.
+// If fn returns, leadership is released and the campaign resumes.
+func (e *LeaderElector) Run(ctx context.Context, fn func(ctx context.Context)) error {
+	for {
+		if err := e.campaign(ctx, fn); err != nil && ctx.Err() == nil {
+			log.Printf("Leader election %q: %v", e.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(e.interval):
+		}
+	}
+}
+
+// campaign tries to take the lock once and, if successful, leads until the lock is lost
+func (e *LeaderElector) campaign(ctx context.Context, fn func(ctx context.Context)) error {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&acquired); err != nil {
+		return fmt.Errorf("failed to try advisory lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+
+	log.Printf("Leader election %q: became leader", e.name)
+	e.leader.Store(true)
+	defer e.leader.Store(false)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(leaderCtx)
+	}()
+
+	err = e.hold(leaderCtx, conn, done)
+	cancel()
+	<-done
+
+	// Session locks survive the connection being returned to the pool, so
+	// unlock explicitly to let another replica take over
+	unlockCtx, unlockCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer unlockCancel()
+	if _, unlockErr := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", e.key); unlockErr != nil {
+		// Drop the connection rather than return it to the pool still holding the lock
+		log.Printf("Leader election %q: failed to unlock, discarding connection: %v", e.name, unlockErr)
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+
+	log.Printf("Leader election %q: stepped down", e.name)
+	return err
+}
+
+// hold pings the lock connection until ctx is cancelled, fn returns or the connection fails
+func (e *LeaderElector) hold(ctx context.Context, conn *sql.Conn, done <-chan struct{}) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-done:
+			return nil
+		case <-ticker.C:
+			if err := conn.PingContext(ctx); err != nil {
+				return fmt.Errorf("lost lock connection: %w", err)
+			}
+		}
+	}
+}
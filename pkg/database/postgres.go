@@ -7,10 +7,11 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/XSAM/otelsql"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
@@ -21,6 +22,7 @@ type Config struct {
 	User            string
 	Password        string
 	Database        string
+	Schema          string // Optional; isolates a service when several share one database
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
@@ -35,6 +37,7 @@ func LoadConfigFromEnv(serviceName string) *Config {
 		User:            getEnv("DB_USER", "docutab"),
 		Password:        getEnv("DB_PASSWORD", "docutab_dev_pass"),
 		Database:        getEnv("DB_NAME", "docutab"),
+		Schema:          os.Getenv("DB_SCHEMA"),
 		MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
 		MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
 		ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
@@ -54,7 +57,14 @@ func NewPostgresDB(ctx context.Context, config *Config) (*sql.DB, error) {
 		config.Database,
 	)
 
-	log.Printf("Connecting to PostgreSQL: host=%s port=%d dbname=%s", config.Host, config.Port, config.Database)
+	// Unqualified table names resolve to the service's schema; public stays on
+	// the path for extensions such as pg_stat_statements
+	if config.Schema != "" {
+		searchPath := pq.QuoteIdentifier(config.Schema) + ",public"
+		connStr += fmt.Sprintf(" search_path='%s'", strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(searchPath))
+	}
+
+	log.Printf("Connecting to PostgreSQL: host=%s port=%d dbname=%s schema=%s", config.Host, config.Port, config.Database, config.Schema)
 
 	// Register the instrumented driver
 	driverName, err := otelsql.Register(
@@ -93,6 +103,13 @@ func NewPostgresDB(ctx context.Context, config *Config) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	if config.Schema != "" {
+		if _, err := db.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+pq.QuoteIdentifier(config.Schema)); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create schema %s: %w", config.Schema, err)
+		}
+	}
+
 	// Record database metrics with OTEL
 	if err := otelsql.RecordStats(db, otelsql.WithAttributes(
 		semconv.DBSystemPostgreSQL,
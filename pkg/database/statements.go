@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// StatementStats is one row of pg_stat_statements; times are in milliseconds,
+// as pg_stat_statements reports them
+type StatementStats struct {
+	Query       string  `json:"query"`
+	Calls       int64   `json:"calls"`
+	Rows        int64   `json:"rows"`
+	TotalTimeMs float64 `json:"total_time_ms"`
+	MeanTimeMs  float64 `json:"mean_time_ms"`
+	MaxTimeMs   float64 `json:"max_time_ms"`
+}
+
+// StatementSnapshot is a point-in-time capture of the most expensive statements
+type StatementSnapshot struct {
+	CapturedAt time.Time        `json:"captured_at"`
+	Statements []StatementStats `json:"statements"`
+}
+
+// TopStatements returns the statements with the highest total execution time for the
+// current database. Requires the pg_stat_statements extension (PostgreSQL 13+ column names).
+func TopStatements(ctx context.Context, db *sql.DB, limit int) (*StatementSnapshot, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT query, calls, rows, total_exec_time, mean_exec_time, max_exec_time
+		FROM pg_stat_statements
+		WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+		  AND query NOT LIKE '%pg_stat_statements%'
+		ORDER BY total_exec_time DESC
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pg_stat_statements: %w", err)
+	}
+	defer rows.Close()
+
+	snapshot := &StatementSnapshot{CapturedAt: time.Now()}
+	for rows.Next() {
+		var s StatementStats
+		if err := rows.Scan(&s.Query, &s.Calls, &s.Rows, &s.TotalTimeMs, &s.MeanTimeMs, &s.MaxTimeMs); err != nil {
+			return nil, fmt.Errorf("failed to scan statement stats: %w", err)
+		}
+		snapshot.Statements = append(snapshot.Statements, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read statement stats: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// StatementRecorder periodically captures TopStatements and serves the latest
+// snapshot as JSON, for mounting on an admin endpoint
+type StatementRecorder struct {
+	db     *sql.DB
+	limit  int
+	mu     sync.RWMutex
+	latest *StatementSnapshot
+}
+
+// NewStatementRecorder creates a recorder keeping the top limit statements
+func NewStatementRecorder(db *sql.DB, limit int) *StatementRecorder {
+	return &StatementRecorder{db: db, limit: limit}
+}
+
+// Start captures a snapshot immediately and then every interval until ctx is cancelled
+func (r *StatementRecorder) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			r.capture(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (r *StatementRecorder) capture(ctx context.Context) {
+	snapshot, err := TopStatements(ctx, r.db, r.limit)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Warning: failed to capture pg_stat_statements: %v", err)
+		}
+		return
+	}
+
+	r.mu.Lock()
+	r.latest = snapshot
+	r.mu.Unlock()
+}
+
+// Latest returns the most recent snapshot, or nil if none has been captured
+func (r *StatementRecorder) Latest() *StatementSnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.latest
+}
+
+// ServeHTTP writes the latest snapshot as JSON
+func (r *StatementRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	snapshot := r.Latest()
+	if snapshot == nil {
+		http.Error(w, `{"error":"no statement snapshot captured yet"}`, http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
@@ -0,0 +1,231 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Flag declares a feature flag and its default state
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+// Pipeline stage and subsystem flags shared by the services
+var (
+	ImageAnalysis = Flag{Name: "image_analysis", Description: "Analyze scraped images with the vision model", Default: true}
+	AIDetection   = Flag{Name: "ai_detection", Description: "Score documents for AI-generated content", Default: true}
+	SEOServing    = Flag{Name: "seo_serving", Description: "Serve public /content pages, sitemaps and robots.txt", Default: true}
+	CrawlMode     = Flag{Name: "crawl_mode", Description: "Follow extracted links when scraping", Default: false}
+)
+
+// Override sets a flag at runtime, for every tenant (Tenant "") or for one tenant
+type Override struct {
+	Flag    string `json:"flag"`
+	Tenant  string `json:"tenant,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Source loads runtime overrides, e.g. from a service's feature_flags table
+type Source interface {
+	LoadOverrides(ctx context.Context) ([]Override, error)
+}
+
+// FlagState is the resolved state of a flag, as shown on the admin endpoint
+type FlagState struct {
+	Flag
+	Env     *bool           `json:"env,omitempty"`
+	Global  *bool           `json:"override,omitempty"`
+	Tenants map[string]bool `json:"tenants,omitempty"`
+	Enabled bool            `json:"enabled"`
+}
+
+// Set evaluates a fixed set of flags. Precedence, highest first: tenant
+// override, global override, FEATURE_<NAME> environment variable, default.
+type Set struct {
+	flags map[string]Flag
+	order []string
+	env   map[string]bool
+
+	mu        sync.RWMutex
+	global    map[string]bool
+	tenants   map[string]map[string]bool
+	loadedAt  time.Time
+	loadError string
+}
+
+// New creates a flag set, reading FEATURE_<NAME> (e.g. FEATURE_IMAGE_ANALYSIS=false) for each flag
+func New(flags ...Flag) *Set {
+	s := &Set{
+		flags:   make(map[string]Flag, len(flags)),
+		env:     make(map[string]bool),
+		global:  make(map[string]bool),
+		tenants: make(map[string]map[string]bool),
+	}
+
+	for _, f := range flags {
+		s.flags[f.Name] = f
+		s.order = append(s.order, f.Name)
+		if value, err := strconv.ParseBool(os.Getenv(EnvVar(f.Name))); err == nil {
+			s.env[f.Name] = value
+		}
+	}
+	sort.Strings(s.order)
+
+	return s
+}
+
+// EnvVar returns the environment variable that sets a flag
+func EnvVar(name string) string {
+	return "FEATURE_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context whose flag evaluations use the tenant's overrides
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set by WithTenant
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// Enabled reports whether a flag is on for the tenant in ctx. Unknown flags are off.
+func (s *Set) Enabled(ctx context.Context, flag Flag) bool {
+	return s.enabled(flag.Name, TenantFromContext(ctx))
+}
+
+func (s *Set) enabled(name, tenant string) bool {
+	f, ok := s.flags[name]
+	if !ok {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if tenant != "" {
+		if value, ok := s.tenants[name][tenant]; ok {
+			return value
+		}
+	}
+	if value, ok := s.global[name]; ok {
+		return value
+	}
+	if value, ok := s.env[name]; ok {
+		return value
+	}
+	return f.Default
+}
+
+// Apply replaces all runtime overrides. Overrides for unknown flags are ignored.
+func (s *Set) Apply(overrides []Override) {
+	global := make(map[string]bool)
+	tenants := make(map[string]map[string]bool)
+
+	for _, o := range overrides {
+		if _, ok := s.flags[o.Flag]; !ok {
+			continue
+		}
+		if o.Tenant == "" {
+			global[o.Flag] = o.Enabled
+			continue
+		}
+		if tenants[o.Flag] == nil {
+			tenants[o.Flag] = make(map[string]bool)
+		}
+		tenants[o.Flag][o.Tenant] = o.Enabled
+	}
+
+	s.mu.Lock()
+	s.global, s.tenants, s.loadedAt, s.loadError = global, tenants, time.Now(), ""
+	s.mu.Unlock()
+}
+
+// Start loads overrides from source immediately and then every interval until ctx is cancelled.
+// On a failed load the previous overrides stay in effect.
+func (s *Set) Start(ctx context.Context, source Source, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.refresh(ctx, source)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Set) refresh(ctx context.Context, source Source) {
+	overrides, err := source.LoadOverrides(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Warning: failed to load feature flag overrides: %v", err)
+			s.mu.Lock()
+			s.loadError = err.Error()
+			s.mu.Unlock()
+		}
+		return
+	}
+	s.Apply(overrides)
+}
+
+// State returns the resolved state of every flag, in name order
+func (s *Set) State() []FlagState {
+	states := make([]FlagState, 0, len(s.order))
+	for _, name := range s.order {
+		state := FlagState{Flag: s.flags[name], Enabled: s.enabled(name, "")}
+
+		s.mu.RLock()
+		if value, ok := s.env[name]; ok {
+			state.Env = &value
+		}
+		if value, ok := s.global[name]; ok {
+			state.Global = &value
+		}
+		if len(s.tenants[name]) > 0 {
+			state.Tenants = make(map[string]bool, len(s.tenants[name]))
+			for tenant, value := range s.tenants[name] {
+				state.Tenants[tenant] = value
+			}
+		}
+		s.mu.RUnlock()
+
+		states = append(states, state)
+	}
+	return states
+}
+
+// ServeHTTP writes the flag state as JSON, for mounting on an admin endpoint
+func (s *Set) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	response := map[string]interface{}{}
+	if !s.loadedAt.IsZero() {
+		response["overrides_loaded_at"] = s.loadedAt
+	}
+	if s.loadError != "" {
+		response["overrides_error"] = s.loadError
+	}
+	s.mu.RUnlock()
+	response["flags"] = s.State()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
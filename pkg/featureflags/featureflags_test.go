@@ -0,0 +1,109 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEnabledPrecedence(t *testing.T) {
+	t.Setenv("FEATURE_IMAGE_ANALYSIS", "false")
+	t.Setenv("FEATURE_CRAWL_MODE", "not-a-bool")
+	flags := New(ImageAnalysis, AIDetection, CrawlMode)
+	ctx := context.Background()
+
+	if flags.Enabled(ctx, ImageAnalysis) {
+		t.Error("Expected env to turn image_analysis off")
+	}
+	if !flags.Enabled(ctx, AIDetection) {
+		t.Error("Expected ai_detection to use its default (on)")
+	}
+	if flags.Enabled(ctx, CrawlMode) {
+		t.Error("Expected invalid env value to be ignored")
+	}
+	if flags.Enabled(ctx, SEOServing) {
+		t.Error("Expected unregistered flag to be off")
+	}
+
+	flags.Apply([]Override{
+		{Flag: "image_analysis", Enabled: true},
+		{Flag: "ai_detection", Tenant: "acme", Enabled: false},
+		{Flag: "unknown", Enabled: true},
+	})
+
+	if !flags.Enabled(ctx, ImageAnalysis) {
+		t.Error("Expected global override to beat env")
+	}
+	if !flags.Enabled(ctx, AIDetection) {
+		t.Error("Expected tenant override not to apply without a tenant")
+	}
+	if flags.Enabled(WithTenant(ctx, "acme"), AIDetection) {
+		t.Error("Expected tenant override to turn ai_detection off for acme")
+	}
+	if !flags.Enabled(WithTenant(ctx, "other"), AIDetection) {
+		t.Error("Expected other tenants to keep the default")
+	}
+}
+
+type fakeSource struct {
+	overrides []Override
+	err       error
+	loads     chan struct{}
+}
+
+func (f *fakeSource) LoadOverrides(ctx context.Context) ([]Override, error) {
+	defer func() { f.loads <- struct{}{} }()
+	return f.overrides, f.err
+}
+
+func TestStartKeepsOverridesOnError(t *testing.T) {
+	flags := New(CrawlMode)
+	source := &fakeSource{overrides: []Override{{Flag: "crawl_mode", Enabled: true}}, loads: make(chan struct{}, 10)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	flags.Start(ctx, source, time.Hour)
+	<-source.loads
+
+	if !flags.Enabled(ctx, CrawlMode) {
+		t.Fatal("Expected override from source to be applied")
+	}
+
+	source.err = errors.New("database unavailable")
+	flags.refresh(ctx, source)
+	<-source.loads
+
+	if !flags.Enabled(ctx, CrawlMode) {
+		t.Error("Expected previous overrides to survive a failed load")
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	t.Setenv("FEATURE_SEO_SERVING", "false")
+	flags := New(SEOServing, CrawlMode)
+	flags.Apply([]Override{{Flag: "crawl_mode", Tenant: "acme", Enabled: true}})
+
+	w := httptest.NewRecorder()
+	flags.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/flags", nil))
+
+	var body struct {
+		Flags []FlagState `json:"flags"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Flags) != 2 {
+		t.Fatalf("Expected 2 flags, got %d", len(body.Flags))
+	}
+
+	crawl, seo := body.Flags[0], body.Flags[1]
+	if crawl.Name != "crawl_mode" || crawl.Enabled || !crawl.Tenants["acme"] {
+		t.Errorf("Unexpected crawl_mode state: %+v", crawl)
+	}
+	if seo.Name != "seo_serving" || seo.Enabled || seo.Env == nil || *seo.Env {
+		t.Errorf("Unexpected seo_serving state: %+v", seo)
+	}
+}
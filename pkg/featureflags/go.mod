@@ -0,0 +1,3 @@
+module github.com/docutag/platform/pkg/featureflags
+
+go 1.24.0
@@ -0,0 +1,262 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HeaderName is the request header carrying the client's idempotency key
+const HeaderName = "Idempotency-Key"
+
+const (
+	statePending  = "pending"
+	stateComplete = "complete"
+)
+
+// record is what is stored in Redis for a key
+type record struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Config configures the idempotency middleware
+type Config struct {
+	// TTL is how long a completed response is replayed for
+	TTL time.Duration
+	// LockTTL bounds how long a key stays locked if the handler never finishes (e.g. the pod dies)
+	LockTTL time.Duration
+	// MaxBodyBytes is the largest request or response body handled; larger requests get 413
+	MaxBodyBytes int64
+	// Scope separates clients so one can't replay another's responses; defaults to the Authorization header
+	Scope func(r *http.Request) string
+	// Prefix namespaces the Redis keys
+	Prefix string
+}
+
+// DefaultConfig keeps responses for 24 hours
+func DefaultConfig() Config {
+	return Config{
+		TTL:          24 * time.Hour,
+		LockTTL:      5 * time.Minute,
+		MaxBodyBytes: 1 << 20,
+		Scope:        func(r *http.Request) string { return r.Header.Get("Authorization") },
+		Prefix:       "idempotency:",
+	}
+}
+
+// withDefaults fills unset fields from DefaultConfig
+func withDefaults(cfg Config) Config {
+	defaults := DefaultConfig()
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaults.TTL
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = defaults.LockTTL
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = defaults.MaxBodyBytes
+	}
+	if cfg.Scope == nil {
+		cfg.Scope = defaults.Scope
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaults.Prefix
+	}
+	return cfg
+}
+
+// storable reports whether a response is final and safe to replay: successes, and client
+// errors that the same request will always get again. Transient failures such as 401,
+// 409, 429 and 5xx release the key so a correct retry runs the handler again.
+func storable(status int) bool {
+	if status >= 200 && status < 300 {
+		return true
+	}
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusGone,
+		http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+// Middleware makes POST requests carrying an Idempotency-Key safe to retry.
+// The first request runs normally and its response is stored; retries with the
+// same key and body get that response back (marked Idempotent-Replayed: true)
+// instead of creating duplicates. Only final responses are stored (see storable), so
+// transient failures can be retried. If Redis is unavailable requests run as if no key was sent.
+func Middleware(client *redis.Client, cfg Config) func(http.Handler) http.Handler {
+	cfg = withDefaults(cfg)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderName)
+			if key == "" || r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > 255 {
+				writeError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, cfg.MaxBodyBytes+1))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "failed to read request body")
+				return
+			}
+			if int64(len(body)) > cfg.MaxBodyBytes {
+				writeError(w, http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("request body exceeds %d bytes, the limit for requests with an Idempotency-Key", cfg.MaxBodyBytes))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			redisKey := cfg.Prefix + hash(cfg.Scope(r), r.URL.Path, key)
+			fingerprint := hash(string(body))
+
+			existing, acquired, err := lock(r.Context(), client, redisKey, fingerprint, cfg.LockTTL)
+			if err != nil {
+				log.Printf("Warning: idempotency store unavailable, running request: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !acquired {
+				switch {
+				case existing.Fingerprint != fingerprint:
+					writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request body")
+				case existing.State == statePending:
+					w.Header().Set("Retry-After", "1")
+					writeError(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+				default:
+					replay(w, existing)
+				}
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK, limit: cfg.MaxBodyBytes}
+			next.ServeHTTP(rec, r)
+
+			// Use a fresh context: the client may have gone away, but the result must still be saved
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if !storable(rec.status) || rec.overflow {
+				if err := client.Del(ctx, redisKey).Err(); err != nil {
+					log.Printf("Warning: failed to release idempotency key: %v", err)
+				}
+				return
+			}
+
+			data, err := json.Marshal(record{
+				State:       stateComplete,
+				Fingerprint: fingerprint,
+				Status:      rec.status,
+				ContentType: rec.Header().Get("Content-Type"),
+				Body:        rec.body.Bytes(),
+			})
+			if err == nil {
+				err = client.Set(ctx, redisKey, data, cfg.TTL).Err()
+			}
+			if err != nil {
+				log.Printf("Warning: failed to store idempotent response: %v", err)
+			}
+		})
+	}
+}
+
+// lock claims the key for this request. If it is already claimed, the existing record is returned.
+func lock(ctx context.Context, client *redis.Client, key, fingerprint string, ttl time.Duration) (*record, bool, error) {
+	pending, err := json.Marshal(record{State: statePending, Fingerprint: fingerprint})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	acquired, err := client.SetNX(ctx, key, pending, ttl).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lock key: %w", err)
+	}
+	if acquired {
+		return nil, true, nil
+	}
+
+	data, err := client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Released between SETNX and GET (the first attempt failed); let this one run
+		return lock(ctx, client, key, fingerprint, ttl)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	var existing record
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, false, fmt.Errorf("failed to decode record: %w", err)
+	}
+	return &existing, false, nil
+}
+
+func replay(w http.ResponseWriter, rec *record) {
+	if rec.ContentType != "" {
+		w.Header().Set("Content-Type", rec.ContentType)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(rec.Status)
+	w.Write(rec.Body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+func hash(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder passes the response through while keeping a copy to store
+type recorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	limit    int64
+	overflow bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if !r.overflow {
+		if int64(r.body.Len()+len(b)) > r.limit {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,213 @@
+package idempotency
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestServer wraps a handler that counts calls and echoes a new record ID
+func newTestServer(t *testing.T, status int) (http.Handler, *miniredis.Miniredis, *int) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	calls := 0
+	var mu sync.Mutex
+	handler := Middleware(client, DefaultConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id":"req-` + string(rune('0'+n)) + `","echo":` + string(body) + `}`))
+	}))
+	return handler, mr, &calls
+}
+
+func post(handler http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/scrape", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	if key != "" {
+		req.Header.Set(HeaderName, key)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestRetryReplaysOriginalResponse(t *testing.T) {
+	handler, _, calls := newTestServer(t, http.StatusCreated)
+
+	first := post(handler, "abc", `{"url":"https://example.com"}`)
+	second := post(handler, "abc", `{"url":"https://example.com"}`)
+
+	if *calls != 1 {
+		t.Fatalf("Expected handler to run once, ran %d times", *calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("Expected replay of %d %q, got %d %q", first.Code, first.Body, second.Code, second.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("Expected Idempotent-Replayed header on replay")
+	}
+	if second.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected content type to be replayed, got %q", second.Header().Get("Content-Type"))
+	}
+
+	// A different key creates a new record
+	post(handler, "def", `{"url":"https://example.com"}`)
+	if *calls != 2 {
+		t.Errorf("Expected new key to run the handler, calls = %d", *calls)
+	}
+}
+
+func TestKeyReusedWithDifferentBody(t *testing.T) {
+	handler, _, calls := newTestServer(t, http.StatusCreated)
+
+	post(handler, "abc", `{"url":"https://example.com/a"}`)
+	w := post(handler, "abc", `{"url":"https://example.com/b"}`)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for reused key, got %d", w.Code)
+	}
+	if *calls != 1 {
+		t.Errorf("Expected handler to run once, ran %d times", *calls)
+	}
+}
+
+func TestOnlyFinalResponsesAreStored(t *testing.T) {
+	tests := []struct {
+		status int
+		stored bool
+	}{
+		{http.StatusCreated, true},
+		{http.StatusBadRequest, true},
+		{http.StatusUnprocessableEntity, true},
+		{http.StatusUnauthorized, false},
+		{http.StatusConflict, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusBadGateway, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			handler, _, calls := newTestServer(t, tt.status)
+
+			post(handler, "abc", `{}`)
+			post(handler, "abc", `{}`)
+
+			want := 2
+			if tt.stored {
+				want = 1
+			}
+			if *calls != want {
+				t.Errorf("Expected %d handler calls for status %d, got %d", want, tt.status, *calls)
+			}
+		})
+	}
+}
+
+func TestZeroConfigUsesDefaults(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	calls := 0
+	handler := Middleware(client, Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	post(handler, "abc", `{}`)
+	if w := post(handler, "abc", `{}`); w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Expected replay with a zero Config, got %d", w.Code)
+	}
+	if calls != 1 {
+		t.Errorf("Expected handler to run once, ran %d times", calls)
+	}
+	if ttl := mr.TTL("idempotency:" + hash("Bearer secret", "/api/scrape", "abc")); ttl != 24*time.Hour {
+		t.Errorf("Expected default TTL and prefix, got TTL %v", ttl)
+	}
+}
+
+func TestOversizedBodyRejected(t *testing.T) {
+	handler, _, calls := newTestServer(t, http.StatusCreated)
+
+	w := post(handler, "abc", `"`+strings.Repeat("x", 1<<20)+`"`)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for oversized body with a key, got %d", w.Code)
+	}
+	if *calls != 0 {
+		t.Errorf("Expected handler not to run, calls = %d", *calls)
+	}
+
+	// Without a key the body isn't buffered, so size is left to the handler
+	if w := post(handler, "", `"`+strings.Repeat("x", 1<<20)+`"`); w.Code != http.StatusCreated {
+		t.Errorf("Expected oversized body without a key to pass through, got %d", w.Code)
+	}
+}
+
+func TestInProgressKeyConflicts(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := Middleware(client, DefaultConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		post(handler, "abc", `{}`)
+		close(done)
+	}()
+	<-started
+
+	w := post(handler, "abc", `{}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while the first request runs, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After on 409")
+	}
+
+	close(release)
+	<-done
+}
+
+func TestPassThrough(t *testing.T) {
+	handler, mr, calls := newTestServer(t, http.StatusCreated)
+
+	// No key: every request runs
+	post(handler, "", `{}`)
+	post(handler, "", `{}`)
+	if *calls != 2 {
+		t.Errorf("Expected requests without a key to run, calls = %d", *calls)
+	}
+
+	// Redis down: requests still run
+	mr.Close()
+	if w := post(handler, "abc", `{}`); w.Code != http.StatusCreated {
+		t.Errorf("Expected request to run with Redis down, got %d", w.Code)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected handler to run with Redis down, calls = %d", *calls)
+	}
+}
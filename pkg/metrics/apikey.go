@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// APIKeyMetrics tracks API usage per key and rejected authentication attempts
+type APIKeyMetrics struct {
+	RequestsTotal     *prometheus.CounterVec
+	AuthFailuresTotal *prometheus.CounterVec
+}
+
+// NewAPIKeyMetrics creates and registers API key metrics for a service
+func NewAPIKeyMetrics(serviceName string) *APIKeyMetrics {
+	constLabels := prometheus.Labels{
+		"service": serviceName,
+		"app":     "docutab",
+	}
+
+	m := &APIKeyMetrics{
+		RequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:        "docutab_api_key_requests_total",
+				Help:        "Total number of authenticated API requests by key",
+				ConstLabels: constLabels,
+			},
+			[]string{"key_id", "status"},
+		),
+		AuthFailuresTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:        "docutab_api_key_auth_failures_total",
+				Help:        "Total number of requests rejected for a missing or invalid API key",
+				ConstLabels: constLabels,
+			},
+			[]string{"reason"},
+		),
+	}
+
+	mustRegister(m.RequestsTotal)
+	mustRegister(m.AuthFailuresTotal)
+
+	return m
+}
+
+// ObserveRequest records a request made with an API key. Labels use the key ID, never the secret.
+func (m *APIKeyMetrics) ObserveRequest(keyID string, status int) {
+	m.RequestsTotal.WithLabelValues(keyID, strconv.Itoa(status)).Inc()
+}
+
+// ObserveAuthFailure records a rejected request (reason is "missing" or "invalid")
+func (m *APIKeyMetrics) ObserveAuthFailure(reason string) {
+	m.AuthFailuresTotal.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// knownCrawlers maps user-agent substrings (lowercase) to crawler names.
+// Order matters: more specific tokens come first.
+var knownCrawlers = []struct {
+	token string
+	name  string
+}{
+	{"googlebot", "googlebot"},
+	{"google-inspectiontool", "googlebot"},
+	{"bingbot", "bingbot"},
+	{"duckduckbot", "duckduckbot"},
+	{"yandexbot", "yandexbot"},
+	{"baiduspider", "baiduspider"},
+	{"applebot", "applebot"},
+	{"slurp", "yahoo"},
+}
+
+// genericCrawlerTokens identify crawlers we don't break out by name
+var genericCrawlerTokens = []string{"bot", "crawler", "spider"}
+
+// ClassifyCrawler returns the crawler name for a user agent, "other" for
+// unrecognised bots, or "" if the user agent doesn't look like a crawler
+func ClassifyCrawler(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return ""
+	}
+
+	for _, c := range knownCrawlers {
+		if strings.Contains(ua, c.token) {
+			return c.name
+		}
+	}
+	for _, token := range genericCrawlerTokens {
+		if strings.Contains(ua, token) {
+			return "other"
+		}
+	}
+	return ""
+}
+
+// seoSection returns the SEO surface a path belongs to, or "" if crawler
+// hits on it aren't tracked
+func seoSection(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/content/"):
+		return "content"
+	case path == "/robots.txt":
+		return "robots"
+	case strings.HasPrefix(path, "/sitemap") && strings.HasSuffix(path, ".xml"),
+		path == "/images-sitemap.xml":
+		return "sitemap"
+	}
+	return ""
+}
+
+// CrawlerStats is the aggregate of hits by one crawler on one SEO section
+type CrawlerStats struct {
+	Crawler  string    `json:"crawler"`
+	Section  string    `json:"section"`
+	Hits     int64     `json:"hits"`
+	Errors   int64     `json:"errors"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+type crawlerKey struct {
+	crawler string
+	section string
+}
+
+// CrawlerMetrics tracks search engine crawler traffic on /content/*, sitemaps and robots.txt
+type CrawlerMetrics struct {
+	HitsTotal *prometheus.CounterVec
+
+	serviceName string
+	mu          sync.Mutex
+	stats       map[crawlerKey]*CrawlerStats
+}
+
+// NewCrawlerMetrics creates and registers crawler metrics for a service
+func NewCrawlerMetrics(serviceName string) *CrawlerMetrics {
+	m := &CrawlerMetrics{
+		HitsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "docutab_crawler_hits_total",
+				Help: "Total number of search engine crawler requests to SEO pages",
+			},
+			[]string{"service", "crawler", "section", "status"},
+		),
+		serviceName: serviceName,
+		stats:       make(map[crawlerKey]*CrawlerStats),
+	}
+
+	mustRegister(m.HitsTotal)
+
+	return m
+}
+
+// Middleware records crawler hits on SEO paths. Requests from regular
+// browsers and requests to other paths pass through untouched.
+func (m *CrawlerMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		section := seoSection(r.URL.Path)
+		if section == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		crawler := ClassifyCrawler(r.UserAgent())
+		if crawler == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(wrapped, r)
+
+		m.record(crawler, section, wrapped.statusCode)
+		slog.InfoContext(r.Context(), "crawler hit",
+			"crawler", crawler,
+			"section", section,
+			"path", r.URL.Path,
+			"status", wrapped.statusCode,
+			"user_agent", r.UserAgent(),
+		)
+	})
+}
+
+func (m *CrawlerMetrics) record(crawler, section string, status int) {
+	m.HitsTotal.WithLabelValues(m.serviceName, crawler, section, strconv.Itoa(status)).Inc()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := crawlerKey{crawler: crawler, section: section}
+	s, ok := m.stats[key]
+	if !ok {
+		s = &CrawlerStats{Crawler: crawler, Section: section}
+		m.stats[key] = s
+	}
+	s.Hits++
+	if status >= http.StatusBadRequest {
+		s.Errors++
+	}
+	s.LastSeen = time.Now()
+}
+
+// Stats returns the aggregated hits since startup, ordered by crawler then section
+func (m *CrawlerMetrics) Stats() []CrawlerStats {
+	m.mu.Lock()
+	stats := make([]CrawlerStats, 0, len(m.stats))
+	for _, s := range m.stats {
+		stats = append(stats, *s)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Crawler != stats[j].Crawler {
+			return stats[i].Crawler < stats[j].Crawler
+		}
+		return stats[i].Section < stats[j].Section
+	})
+	return stats
+}
+
+// ServeHTTP writes the aggregated crawler stats as JSON, for mounting at /api/stats/crawlers
+func (m *CrawlerMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"crawlers": m.Stats(),
+	})
+}
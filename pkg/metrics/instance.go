@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// instanceLabelEnv maps metric label names to the environment variables the
+// Helm chart populates from the Kubernetes downward API
+var instanceLabelEnv = map[string]string{
+	"pod":       "POD_NAME",
+	"namespace": "POD_NAMESPACE",
+}
+
+// InstanceLabels returns pod and namespace labels from the downward API.
+// Returns nil outside Kubernetes so local and docker-compose metrics are unchanged.
+func InstanceLabels() prometheus.Labels {
+	var labels prometheus.Labels
+	for label, env := range instanceLabelEnv {
+		if value := os.Getenv(env); value != "" {
+			if labels == nil {
+				labels = prometheus.Labels{}
+			}
+			labels[label] = value
+		}
+	}
+	return labels
+}
+
+// mustRegister registers collectors with the default registerer, attaching
+// instance labels so series from multiple replicas don't collide
+func mustRegister(cs ...prometheus.Collector) {
+	prometheus.WrapRegistererWith(InstanceLabels(), prometheus.DefaultRegisterer).MustRegister(cs...)
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LLMOutputMetrics tracks how often model output fails to parse or validate, per pipeline stage
+type LLMOutputMetrics struct {
+	OutputsTotal *prometheus.CounterVec
+}
+
+// NewLLMOutputMetrics creates and registers structured output metrics for a service
+func NewLLMOutputMetrics(serviceName string) *LLMOutputMetrics {
+	m := &LLMOutputMetrics{
+		OutputsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "docutab_llm_structured_outputs_total",
+				Help: "Total number of structured LLM outputs by stage and outcome (valid, repaired, reprompted, invalid, quarantined)",
+				ConstLabels: prometheus.Labels{
+					"service": serviceName,
+					"app":     "docutab",
+				},
+			},
+			[]string{"stage", "outcome"},
+		),
+	}
+
+	mustRegister(m.OutputsTotal)
+
+	return m
+}
+
+// ObserveOutput records the outcome of decoding one model output for a stage
+func (m *LLMOutputMetrics) ObserveOutput(stage, outcome string) {
+	m.OutputsTotal.WithLabelValues(stage, outcome).Inc()
+}
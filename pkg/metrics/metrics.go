@@ -3,9 +3,12 @@ package metrics
 import (
 	"context"
 	"database/sql"
+	"log/slog"
 	"net/http"
+	"os"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -98,10 +101,10 @@ func NewBusinessMetrics(serviceName string) *BusinessMetrics {
 				Help: "Current number of jobs in the queue",
 			},
 		)
-		prometheus.MustRegister(m.ScrapeRequestsTotal)
-		prometheus.MustRegister(m.ScrapeJobsTotal)
-		prometheus.MustRegister(m.ScrapeJobsByStatus)
-		prometheus.MustRegister(m.QueueLength)
+		mustRegister(m.ScrapeRequestsTotal)
+		mustRegister(m.ScrapeJobsTotal)
+		mustRegister(m.ScrapeJobsByStatus)
+		mustRegister(m.QueueLength)
 
 		// Tombstone metrics
 		m.TombstonesCreatedTotal = prometheus.NewCounterVec(
@@ -125,9 +128,9 @@ func NewBusinessMetrics(serviceName string) *BusinessMetrics {
 			},
 			[]string{"reason"}, // low-score|tag-based|manual
 		)
-		prometheus.MustRegister(m.TombstonesCreatedTotal)
-		prometheus.MustRegister(m.TombstonesPending)
-		prometheus.MustRegister(m.TombstoneDaysHistogram)
+		mustRegister(m.TombstonesCreatedTotal)
+		mustRegister(m.TombstonesPending)
+		mustRegister(m.TombstoneDaysHistogram)
 
 		// Document metrics
 		m.DocumentsTotal = prometheus.NewGaugeVec(
@@ -155,10 +158,10 @@ func NewBusinessMetrics(serviceName string) *BusinessMetrics {
 				Help: "Number of documents with SEO enabled",
 			},
 		)
-		prometheus.MustRegister(m.DocumentsTotal)
-		prometheus.MustRegister(m.DocumentsWithTags)
-		prometheus.MustRegister(m.UniqueTagsTotal)
-		prometheus.MustRegister(m.DocumentsWithSEO)
+		mustRegister(m.DocumentsTotal)
+		mustRegister(m.DocumentsWithTags)
+		mustRegister(m.UniqueTagsTotal)
+		mustRegister(m.DocumentsWithSEO)
 
 	case "scraper":
 		m.ScrapesCompletedTotal = prometheus.NewCounterVec(
@@ -207,13 +210,13 @@ func NewBusinessMetrics(serviceName string) *BusinessMetrics {
 				Help: "Total storage size in bytes for all images",
 			},
 		)
-		prometheus.MustRegister(m.ScrapesCompletedTotal)
-		prometheus.MustRegister(m.LinksExtractedTotal)
-		prometheus.MustRegister(m.ImagesProcessedTotal)
-		prometheus.MustRegister(m.ImagesTotalStored)
-		prometheus.MustRegister(m.ImagesStorageBytes)
-		prometheus.MustRegister(m.OllamaRequestsTotal)
-		prometheus.MustRegister(m.ScrapeDuration)
+		mustRegister(m.ScrapesCompletedTotal)
+		mustRegister(m.LinksExtractedTotal)
+		mustRegister(m.ImagesProcessedTotal)
+		mustRegister(m.ImagesTotalStored)
+		mustRegister(m.ImagesStorageBytes)
+		mustRegister(m.OllamaRequestsTotal)
+		mustRegister(m.ScrapeDuration)
 
 	case "textanalyzer":
 		m.AnalysesTotal = prometheus.NewCounterVec(
@@ -250,11 +253,11 @@ func NewBusinessMetrics(serviceName string) *BusinessMetrics {
 			},
 			[]string{"status"},
 		)
-		prometheus.MustRegister(m.AnalysesTotal)
-		prometheus.MustRegister(m.TagsGeneratedTotal)
-		prometheus.MustRegister(m.SynopsisGeneratedTotal)
-		prometheus.MustRegister(m.AnalyzerOllamaRequests)
-		prometheus.MustRegister(m.AnalysisDuration)
+		mustRegister(m.AnalysesTotal)
+		mustRegister(m.TagsGeneratedTotal)
+		mustRegister(m.SynopsisGeneratedTotal)
+		mustRegister(m.AnalyzerOllamaRequests)
+		mustRegister(m.AnalysisDuration)
 
 	case "scheduler":
 		m.TasksScheduledTotal = prometheus.NewCounterVec(
@@ -284,10 +287,10 @@ func NewBusinessMetrics(serviceName string) *BusinessMetrics {
 				Help: "Number of currently active tasks",
 			},
 		)
-		prometheus.MustRegister(m.TasksScheduledTotal)
-		prometheus.MustRegister(m.TasksExecutedTotal)
-		prometheus.MustRegister(m.TaskFailuresTotal)
-		prometheus.MustRegister(m.ActiveTasks)
+		mustRegister(m.TasksScheduledTotal)
+		mustRegister(m.TasksExecutedTotal)
+		mustRegister(m.TaskFailuresTotal)
+		mustRegister(m.ActiveTasks)
 	}
 
 	return m
@@ -335,11 +338,20 @@ type DatabaseMetrics struct {
 	WaitCount          prometheus.Counter
 	WaitDuration       prometheus.Counter
 	QueryDuration      *prometheus.HistogramVec
+
+	// SlowQueryThreshold is the duration above which ObserveQuery logs the query (0 disables)
+	SlowQueryThreshold time.Duration
+
+	// Last cumulative values seen by UpdateDBStats, so counters only receive deltas
+	statsMu          sync.Mutex
+	lastWaitCount    int64
+	lastWaitDuration time.Duration
 }
 
 // NewDatabaseMetrics creates and registers database metrics for a specific service
 func NewDatabaseMetrics(serviceName string) *DatabaseMetrics {
 	m := &DatabaseMetrics{
+		SlowQueryThreshold: slowQueryThresholdFromEnv(),
 		ConnectionsOpen: prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "db_connections_open",
@@ -404,24 +416,94 @@ func NewDatabaseMetrics(serviceName string) *DatabaseMetrics {
 		),
 	}
 
-	prometheus.MustRegister(m.ConnectionsOpen)
-	prometheus.MustRegister(m.ConnectionsIdle)
-	prometheus.MustRegister(m.ConnectionsInUse)
-	prometheus.MustRegister(m.WaitCount)
-	prometheus.MustRegister(m.WaitDuration)
-	prometheus.MustRegister(m.QueryDuration)
+	mustRegister(m.ConnectionsOpen)
+	mustRegister(m.ConnectionsIdle)
+	mustRegister(m.ConnectionsInUse)
+	mustRegister(m.WaitCount)
+	mustRegister(m.WaitDuration)
+	mustRegister(m.QueryDuration)
 
 	return m
 }
 
-// UpdateDBStats updates database connection pool metrics from sql.DBStats
+// UpdateDBStats updates database connection pool metrics from sql.DBStats.
+// sql.DBStats wait values are cumulative, so only the increase since the previous call is added.
 func (m *DatabaseMetrics) UpdateDBStats(db *sql.DB) {
 	stats := db.Stats()
 	m.ConnectionsOpen.Set(float64(stats.OpenConnections))
 	m.ConnectionsIdle.Set(float64(stats.Idle))
 	m.ConnectionsInUse.Set(float64(stats.InUse))
-	m.WaitCount.Add(float64(stats.WaitCount))
-	m.WaitDuration.Add(stats.WaitDuration.Seconds())
+
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	// A lower value means a different *sql.DB was passed in; count it from zero
+	if stats.WaitCount < m.lastWaitCount || stats.WaitDuration < m.lastWaitDuration {
+		m.lastWaitCount, m.lastWaitDuration = 0, 0
+	}
+	m.WaitCount.Add(float64(stats.WaitCount - m.lastWaitCount))
+	m.WaitDuration.Add((stats.WaitDuration - m.lastWaitDuration).Seconds())
+	m.lastWaitCount, m.lastWaitDuration = stats.WaitCount, stats.WaitDuration
+}
+
+// StartStatsLoop calls UpdateDBStats immediately and then every interval until ctx is cancelled
+func (m *DatabaseMetrics) StartStatsLoop(ctx context.Context, db *sql.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			m.UpdateDBStats(db)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ObserveQuery records the duration of a storage operation since start and logs it
+// with its SQL and trace ID when it exceeds SlowQueryThreshold.
+// Intended to be deferred at the top of storage methods:
+//
+//	defer m.ObserveQuery(ctx, "GetRequest", query, time.Now())
+func (m *DatabaseMetrics) ObserveQuery(ctx context.Context, operation, query string, start time.Time) {
+	duration := time.Since(start)
+
+	observer := m.QueryDuration.WithLabelValues(operation)
+	spanCtx := trace.SpanFromContext(ctx).SpanContext()
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok && spanCtx.IsValid() {
+		exemplarObserver.ObserveWithExemplar(duration.Seconds(), prometheus.Labels{"trace_id": spanCtx.TraceID().String()})
+	} else {
+		observer.Observe(duration.Seconds())
+	}
+
+	if m.SlowQueryThreshold <= 0 || duration < m.SlowQueryThreshold {
+		return
+	}
+
+	attrs := []any{
+		"operation", operation,
+		"duration_ms", duration.Milliseconds(),
+		"threshold_ms", m.SlowQueryThreshold.Milliseconds(),
+		"sql", query,
+	}
+	if spanCtx.IsValid() {
+		attrs = append(attrs, "trace_id", spanCtx.TraceID().String())
+	}
+	slog.WarnContext(ctx, "slow query", attrs...)
+}
+
+// slowQueryThresholdFromEnv reads DB_SLOW_QUERY_THRESHOLD (e.g. "250ms"), defaulting to 500ms
+func slowQueryThresholdFromEnv() time.Duration {
+	if value := os.Getenv("DB_SLOW_QUERY_THRESHOLD"); value != "" {
+		if threshold, err := time.ParseDuration(value); err == nil {
+			return threshold
+		}
+	}
+	return 500 * time.Millisecond
 }
 
 var (
@@ -473,10 +555,10 @@ func HTTPMiddleware(serviceName string) func(http.Handler) http.Handler {
 			[]string{"service", "method", "path"},
 		)
 
-		prometheus.MustRegister(httpRequestsTotal)
-		prometheus.MustRegister(httpRequestDuration)
-		prometheus.MustRegister(httpRequestSize)
-		prometheus.MustRegister(httpResponseSize)
+		mustRegister(httpRequestsTotal)
+		mustRegister(httpRequestDuration)
+		mustRegister(httpRequestSize)
+		mustRegister(httpResponseSize)
 	})
 
 	// Create per-service active requests gauge
@@ -493,7 +575,7 @@ func HTTPMiddleware(serviceName string) func(http.Handler) http.Handler {
 				},
 			},
 		)
-		prometheus.MustRegister(httpRequestsActive)
+		mustRegister(httpRequestsActive)
 		httpRequestsActiveByService[serviceName] = httpRequestsActive
 	}
 	httpRequestsActiveMutex.Unlock()
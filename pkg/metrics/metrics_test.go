@@ -1,12 +1,16 @@
 package metrics
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
-	// "net/http"
-	// "net/http/httptest"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
-	// "time"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	// "github.com/prometheus/client_golang/prometheus/promhttp"
@@ -694,3 +698,323 @@ func TestSchedulerMetrics(t *testing.T) {
 		t.Errorf("unexpected active tasks metric: %v", err)
 	}
 }
+
+// TestInstanceLabels tests that downward API env vars become labels on registered metrics
+func TestInstanceLabels(t *testing.T) {
+	t.Setenv("POD_NAME", "controller-7d9f8-abcde")
+	t.Setenv("POD_NAMESPACE", "docutag")
+
+	reg := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = reg
+	metrics := NewDatabaseMetrics("test-service")
+	metrics.ConnectionsOpen.Set(3)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	found := false
+	for _, family := range families {
+		if family.GetName() != "db_connections_open" {
+			continue
+		}
+		found = true
+
+		labels := map[string]string{}
+		for _, pair := range family.GetMetric()[0].GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+		if labels["pod"] != "controller-7d9f8-abcde" {
+			t.Errorf("Expected pod label, got %v", labels)
+		}
+		if labels["namespace"] != "docutag" {
+			t.Errorf("Expected namespace label, got %v", labels)
+		}
+		if labels["service"] != "test-service" {
+			t.Errorf("Expected existing service label to be kept, got %v", labels)
+		}
+	}
+
+	if !found {
+		t.Error("db_connections_open metric not found")
+	}
+}
+
+// TestInstanceLabelsOutsideKubernetes tests that no labels are added without downward API env vars
+func TestInstanceLabelsOutsideKubernetes(t *testing.T) {
+	t.Setenv("POD_NAME", "")
+	t.Setenv("POD_NAMESPACE", "")
+
+	if labels := InstanceLabels(); labels != nil {
+		t.Errorf("Expected no instance labels, got %v", labels)
+	}
+}
+
+// TestObserveQuery tests query duration recording and slow query logging
+func TestObserveQuery(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = reg
+	metrics := NewDatabaseMetrics("test-service")
+	metrics.SlowQueryThreshold = 50 * time.Millisecond
+
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(previous)
+
+	ctx := context.Background()
+	metrics.ObserveQuery(ctx, "GetRequest", "SELECT * FROM requests WHERE id = $1", time.Now())
+	if logs.Len() != 0 {
+		t.Errorf("Expected fast query not to be logged, got %q", logs.String())
+	}
+
+	metrics.ObserveQuery(ctx, "ListRequests", "SELECT * FROM requests LIMIT $1", time.Now().Add(-time.Second))
+	if !strings.Contains(logs.String(), "slow query") || !strings.Contains(logs.String(), "operation=ListRequests") {
+		t.Errorf("Expected slow query log for ListRequests, got %q", logs.String())
+	}
+
+	if count := testutil.CollectAndCount(metrics.QueryDuration, "db_query_duration_seconds"); count != 2 {
+		t.Errorf("Expected 2 operation series, got %d", count)
+	}
+}
+
+// TestUpdateDBStatsWaitDeltas tests that cumulative wait stats are not re-added on every update
+func TestUpdateDBStatsWaitDeltas(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	metrics := NewDatabaseMetrics("test-service")
+
+	// Hold the only connection so the next request has to wait for it
+	ctx := context.Background()
+	held, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		conn, err := db.Conn(ctx)
+		if err == nil {
+			conn.Close()
+		}
+		close(acquired)
+	}()
+
+	// Wait until the second request is blocked on the pool
+	deadline := time.Now().Add(time.Second)
+	for db.Stats().WaitCount == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Second connection request never waited on the pool")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	held.Close()
+	<-acquired
+
+	for i := 0; i < 3; i++ {
+		metrics.UpdateDBStats(db)
+	}
+
+	want := float64(db.Stats().WaitCount)
+	if want != 1 {
+		t.Fatalf("Expected exactly one pool wait, got %v", want)
+	}
+	if got := testutil.ToFloat64(metrics.WaitCount); got != want {
+		t.Errorf("Expected wait count %v after repeated updates, got %v", want, got)
+	}
+	if got := testutil.ToFloat64(metrics.WaitDuration); got != db.Stats().WaitDuration.Seconds() {
+		t.Errorf("Expected wait duration %v, got %v", db.Stats().WaitDuration.Seconds(), got)
+	}
+}
+
+// TestStartStatsLoop tests that the stats loop updates gauges and stops with its context
+func TestStartStatsLoop(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Fatalf("Failed to ping database: %v", err)
+	}
+
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	metrics := NewDatabaseMetrics("test-service")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	metrics.StartStatsLoop(ctx, db, 10*time.Millisecond)
+
+	deadline := time.Now().Add(time.Second)
+	for testutil.ToFloat64(metrics.ConnectionsOpen) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected db_connections_open to reach 1, got %v", testutil.ToFloat64(metrics.ConnectionsOpen))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestClassifyCrawler tests user agent classification
+func TestClassifyCrawler(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		expected  string
+	}{
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "googlebot"},
+		{"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)", "bingbot"},
+		{"Mozilla/5.0 (compatible; AhrefsBot/7.0; +http://ahrefs.com/robot/)", "other"},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyCrawler(tt.userAgent); got != tt.expected {
+			t.Errorf("ClassifyCrawler(%q) = %q, expected %q", tt.userAgent, got, tt.expected)
+		}
+	}
+}
+
+// TestCrawlerMiddleware tests that only crawler hits on SEO paths are recorded
+func TestCrawlerMiddleware(t *testing.T) {
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	metrics := NewCrawlerMetrics("test-service")
+
+	handler := metrics.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/content/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	googlebot := "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+	requests := []struct {
+		path      string
+		userAgent string
+	}{
+		{"/content/abc-123", googlebot},
+		{"/content/missing", googlebot},
+		{"/sitemap.xml", googlebot},
+		{"/images-sitemap.xml", googlebot},
+		{"/robots.txt", "Mozilla/5.0 (compatible; bingbot/2.0)"},
+		{"/content/abc-123", "Mozilla/5.0 (X11; Linux x86_64) Firefox/121.0"},
+		{"/api/requests", googlebot},
+	}
+	for _, r := range requests {
+		req := httptest.NewRequest("GET", r.path, nil)
+		req.Header.Set("User-Agent", r.userAgent)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	expected := `
+		# HELP docutab_crawler_hits_total Total number of search engine crawler requests to SEO pages
+		# TYPE docutab_crawler_hits_total counter
+		docutab_crawler_hits_total{crawler="bingbot",section="robots",service="test-service",status="200"} 1
+		docutab_crawler_hits_total{crawler="googlebot",section="content",service="test-service",status="200"} 1
+		docutab_crawler_hits_total{crawler="googlebot",section="content",service="test-service",status="404"} 1
+		docutab_crawler_hits_total{crawler="googlebot",section="sitemap",service="test-service",status="200"} 2
+	`
+	if err := testutil.CollectAndCompare(metrics.HitsTotal, strings.NewReader(expected)); err != nil {
+		t.Errorf("unexpected metric value: %v", err)
+	}
+
+	stats := metrics.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 crawler/section aggregates, got %d: %+v", len(stats), stats)
+	}
+	if stats[1].Crawler != "googlebot" || stats[1].Section != "content" || stats[1].Hits != 2 || stats[1].Errors != 1 {
+		t.Errorf("Unexpected googlebot content stats: %+v", stats[1])
+	}
+}
+
+// TestCrawlerStatsHandler tests the JSON stats endpoint
+func TestCrawlerStatsHandler(t *testing.T) {
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	metrics := NewCrawlerMetrics("test-service")
+	metrics.record("googlebot", "content", http.StatusOK)
+
+	w := httptest.NewRecorder()
+	metrics.ServeHTTP(w, httptest.NewRequest("GET", "/api/stats/crawlers", nil))
+
+	var body struct {
+		Crawlers []CrawlerStats `json:"crawlers"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Crawlers) != 1 || body.Crawlers[0].Hits != 1 {
+		t.Errorf("Unexpected stats response: %+v", body.Crawlers)
+	}
+}
+
+// TestNewModelMetrics tests Ollama model availability metrics
+func TestNewModelMetrics(t *testing.T) {
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	metrics := NewModelMetrics("textanalyzer")
+
+	metrics.Available.WithLabelValues("gemma3:4b").Set(0)
+	metrics.UnavailableSeconds.WithLabelValues("gemma3:4b").Add(30)
+	metrics.PullsTotal.WithLabelValues("gemma3:4b", "success").Inc()
+
+	expected := `
+		# HELP docutab_ollama_model_available Whether a required Ollama model is present (1) or missing (0)
+		# TYPE docutab_ollama_model_available gauge
+		docutab_ollama_model_available{app="docutab",model="gemma3:4b",service="textanalyzer"} 0
+	`
+	if err := testutil.CollectAndCompare(metrics.Available, strings.NewReader(expected)); err != nil {
+		t.Errorf("unexpected metric value: %v", err)
+	}
+
+	if v := testutil.ToFloat64(metrics.UnavailableSeconds.WithLabelValues("gemma3:4b")); v != 30 {
+		t.Errorf("Expected 30 unavailable seconds, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.PullsTotal.WithLabelValues("gemma3:4b", "success")); v != 1 {
+		t.Errorf("Expected 1 pull, got %v", v)
+	}
+}
+
+// TestAPIKeyMetrics tests per-key usage and auth failure counters
+func TestAPIKeyMetrics(t *testing.T) {
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	metrics := NewAPIKeyMetrics("controller")
+
+	metrics.ObserveRequest("ingest-bot", 200)
+	metrics.ObserveRequest("ingest-bot", 200)
+	metrics.ObserveRequest("ingest-bot", 429)
+	metrics.ObserveAuthFailure("invalid")
+
+	expected := `
+		# HELP docutab_api_key_requests_total Total number of authenticated API requests by key
+		# TYPE docutab_api_key_requests_total counter
+		docutab_api_key_requests_total{app="docutab",key_id="ingest-bot",service="controller",status="200"} 2
+		docutab_api_key_requests_total{app="docutab",key_id="ingest-bot",service="controller",status="429"} 1
+	`
+	if err := testutil.CollectAndCompare(metrics.RequestsTotal, strings.NewReader(expected)); err != nil {
+		t.Errorf("unexpected metric value: %v", err)
+	}
+
+	if v := testutil.ToFloat64(metrics.AuthFailuresTotal.WithLabelValues("invalid")); v != 1 {
+		t.Errorf("Expected 1 auth failure, got %v", v)
+	}
+}
+
+func TestLLMOutputMetrics(t *testing.T) {
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+
+	m := NewLLMOutputMetrics("textanalyzer")
+	m.ObserveOutput("tags", "valid")
+	m.ObserveOutput("tags", "repaired")
+	m.ObserveOutput("tags", "repaired")
+
+	if v := testutil.ToFloat64(m.OutputsTotal.WithLabelValues("tags", "repaired")); v != 2 {
+		t.Errorf("Expected 2 repaired outputs, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.OutputsTotal.WithLabelValues("tags", "invalid")); v != 0 {
+		t.Errorf("Expected 0 invalid outputs, got %v", v)
+	}
+}
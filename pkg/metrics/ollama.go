@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ModelMetrics tracks availability of the Ollama models a service depends on
+type ModelMetrics struct {
+	Available          *prometheus.GaugeVec
+	UnavailableSeconds *prometheus.CounterVec
+	PullsTotal         *prometheus.CounterVec
+}
+
+// NewModelMetrics creates and registers Ollama model availability metrics for a service
+func NewModelMetrics(serviceName string) *ModelMetrics {
+	constLabels := prometheus.Labels{
+		"service": serviceName,
+		"app":     "docutab",
+	}
+
+	m := &ModelMetrics{
+		Available: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name:        "docutab_ollama_model_available",
+				Help:        "Whether a required Ollama model is present (1) or missing (0)",
+				ConstLabels: constLabels,
+			},
+			[]string{"model"},
+		),
+		UnavailableSeconds: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:        "docutab_ollama_model_unavailable_seconds_total",
+				Help:        "Total time a required Ollama model was missing or Ollama was unreachable",
+				ConstLabels: constLabels,
+			},
+			[]string{"model"},
+		),
+		PullsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:        "docutab_ollama_model_pulls_total",
+				Help:        "Total number of automatic Ollama model pulls",
+				ConstLabels: constLabels,
+			},
+			[]string{"model", "status"},
+		),
+	}
+
+	mustRegister(m.Available)
+	mustRegister(m.UnavailableSeconds)
+	mustRegister(m.PullsTotal)
+
+	return m
+}
@@ -0,0 +1,113 @@
+package ollama
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/docutag/platform/pkg/metrics"
+)
+
+// injectionMarkers are phrases and chat-template tokens that show up in output when
+// scraped content has hijacked the prompt or the model has echoed its own framing
+var injectionMarkers = []string{
+	"ignore previous instructions",
+	"ignore all previous instructions",
+	"disregard the above",
+	"as an ai language model",
+	"my instructions are",
+	"<|im_start|>",
+	"<|im_end|>",
+	"<start_of_turn>",
+	"<end_of_turn>",
+	"[inst]",
+	"### instruction",
+}
+
+// minLeakWords is how many consecutive system prompt words must appear in the output
+// to count as a leak; shorter runs match ordinary phrasing by chance
+const minLeakWords = 8
+
+// Verdict is the result of screening one output
+type Verdict struct {
+	Quarantined bool     `json:"quarantined"`
+	Reasons     []string `json:"reasons,omitempty"`
+}
+
+// Guard screens generated text before it is stored or served publicly. Flagged
+// output should be kept in a quarantine state for review rather than published.
+type Guard struct {
+	SystemPrompts []string                  // prompts whose text must not appear in output
+	Blocklist     []string                  // terms that are never published, matched case-insensitively
+	Metrics       *metrics.LLMOutputMetrics // optional
+}
+
+// Screen checks output from the given pipeline stage
+func (g Guard) Screen(stage, output string) Verdict {
+	var v Verdict
+	lower := strings.ToLower(output)
+
+	for _, marker := range injectionMarkers {
+		if strings.Contains(lower, marker) {
+			v.Reasons = append(v.Reasons, "prompt injection artifact: "+marker)
+		}
+	}
+
+	words := normalizeWords(output)
+	for _, prompt := range g.SystemPrompts {
+		if leaksPrompt(words, normalizeWords(prompt)) {
+			v.Reasons = append(v.Reasons, "system prompt leaked")
+			break
+		}
+	}
+
+	for _, term := range g.Blocklist {
+		if term = strings.ToLower(strings.TrimSpace(term)); term != "" && containsWord(words, term) {
+			v.Reasons = append(v.Reasons, "blocked term: "+term)
+		}
+	}
+
+	v.Quarantined = len(v.Reasons) > 0
+	if v.Quarantined && g.Metrics != nil {
+		g.Metrics.ObserveOutput(stage, "quarantined")
+	}
+	return v
+}
+
+// ParseBlocklist splits a comma-separated list of blocked terms
+func ParseBlocklist(value string) []string {
+	var terms []string
+	for _, term := range strings.Split(value, ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// normalizeWords lowercases text and splits it into words, dropping punctuation
+func normalizeWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	})
+}
+
+// leaksPrompt reports whether any minLeakWords-long run of prompt words appears in output
+func leaksPrompt(output, prompt []string) bool {
+	if len(prompt) < minLeakWords || len(output) < minLeakWords {
+		return false
+	}
+
+	joined := " " + strings.Join(output, " ") + " "
+	for i := 0; i+minLeakWords <= len(prompt); i++ {
+		if strings.Contains(joined, " "+strings.Join(prompt[i:i+minLeakWords], " ")+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWord reports whether term (one or more words) appears on word boundaries
+func containsWord(words []string, term string) bool {
+	joined := " " + strings.Join(words, " ") + " "
+	return strings.Contains(joined, " "+strings.Join(normalizeWords(term), " ")+" ")
+}
@@ -0,0 +1,265 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docutag/platform/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeOllama is a test Ollama server with a mutable model list
+type fakeOllama struct {
+	*httptest.Server
+	name string
+
+	mu     sync.Mutex
+	models []string
+	pulled []string
+}
+
+func newFakeOllama(t *testing.T, name string, models ...string) *fakeOllama {
+	t.Helper()
+
+	f := &fakeOllama{name: name, models: models}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/tags", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		list := []map[string]string{}
+		for _, m := range f.models {
+			list = append(list, map[string]string{"name": m})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"models": list})
+	})
+	mux.HandleFunc("/api/generate", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"response": "from " + f.name})
+	})
+	mux.HandleFunc("/api/pull", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.pulled = append(f.pulled, req.Model)
+		f.models = append(f.models, req.Model)
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func generate(t *testing.T, client *http.Client, model string) (string, error) {
+	t.Helper()
+
+	resp, err := client.Post("http://ollama/api/generate", "application/json",
+		strings.NewReader(`{"model":"`+model+`","prompt":"hi"}`))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Response string `json:"response"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	return body.Response, nil
+}
+
+func TestPoolRoutesByModel(t *testing.T) {
+	text := newFakeOllama(t, "text", "gemma3:4b")
+	vision := newFakeOllama(t, "vision", "llava:latest")
+
+	pool, err := NewPool([]string{text.URL, vision.URL})
+	if err != nil {
+		t.Fatalf("NewPool() error: %v", err)
+	}
+	pool.checkAll(context.Background())
+	client := &http.Client{Transport: pool}
+
+	if got, err := generate(t, client, "llava"); err != nil || got != "from vision" {
+		t.Errorf("Expected llava request on vision host, got %q, %v", got, err)
+	}
+	if got, err := generate(t, client, "gemma3:4b"); err != nil || got != "from text" {
+		t.Errorf("Expected gemma3 request on text host, got %q, %v", got, err)
+	}
+	if _, err := generate(t, client, "mistral"); !errors.Is(err, ErrNoEndpoint) {
+		t.Errorf("Expected ErrNoEndpoint for a model no host has, got %v", err)
+	}
+}
+
+func TestPoolPrefersLeastLoaded(t *testing.T) {
+	a := newFakeOllama(t, "a", "gemma3:4b")
+	b := newFakeOllama(t, "b", "gemma3:4b")
+
+	pool, _ := NewPool([]string{a.URL, b.URL})
+	pool.checkAll(context.Background())
+
+	// Simulate a long-running generation holding host a
+	pool.endpoints[0].inFlight.Add(1)
+
+	client := &http.Client{Transport: pool}
+	for i := 0; i < 3; i++ {
+		if got, _ := generate(t, client, "gemma3:4b"); got != "from b" {
+			t.Errorf("Expected request %d on less loaded host b, got %q", i, got)
+		}
+	}
+	if n := pool.endpoints[1].inFlight.Load(); n != 0 {
+		t.Errorf("Expected in-flight count to return to 0 after responses are closed, got %d", n)
+	}
+}
+
+func TestPoolFailsOver(t *testing.T) {
+	down := newFakeOllama(t, "down", "gemma3:4b")
+	up := newFakeOllama(t, "up", "gemma3:4b")
+
+	pool, _ := NewPool([]string{down.URL, up.URL})
+	pool.checkAll(context.Background())
+	down.Close()
+
+	client := &http.Client{Transport: pool}
+	if got, err := generate(t, client, "gemma3:4b"); err != nil || got != "from up" {
+		t.Fatalf("Expected failover to healthy host, got %q, %v", got, err)
+	}
+
+	status := pool.Status()
+	if status[0].Healthy || status[0].Error == "" {
+		t.Errorf("Expected failed host to be marked unhealthy, got %+v", status[0])
+	}
+	if !status[1].Healthy {
+		t.Errorf("Expected healthy host to stay healthy, got %+v", status[1])
+	}
+}
+
+func TestPoolRoutesBeforeFirstCheck(t *testing.T) {
+	host := newFakeOllama(t, "host", "gemma3:4b")
+
+	pool, _ := NewPool([]string{host.URL})
+	if pool.pick("llama3", map[*endpoint]bool{}) == nil {
+		t.Fatal("Expected endpoint with unknown models to be routable")
+	}
+
+	client := &http.Client{Transport: pool}
+	if got, err := generate(t, client, "gemma3:4b"); err != nil || got != "from host" {
+		t.Errorf("Expected request before the first health check to be routed, got %q, %v", got, err)
+	}
+}
+
+func TestPoolRoutesPullForMissingModel(t *testing.T) {
+	host := newFakeOllama(t, "host", "gemma3:4b")
+
+	pool, _ := NewPool([]string{host.URL})
+	pool.checkAll(context.Background())
+	client := &http.Client{Transport: pool}
+
+	resp, err := client.Post("http://ollama/api/pull", "application/json", strings.NewReader(`{"model":"llava"}`))
+	if err != nil {
+		t.Fatalf("Expected pull of a model no host has to be routed, got %v", err)
+	}
+	resp.Body.Close()
+
+	host.mu.Lock()
+	defer host.mu.Unlock()
+	if len(host.pulled) != 1 || host.pulled[0] != "llava" {
+		t.Errorf("Expected llava pulled on host, got %v", host.pulled)
+	}
+}
+
+func TestNewPoolFromEnv(t *testing.T) {
+	t.Setenv("OLLAMA_URLS", "http://gpu-1:11434, http://gpu-2:11434/")
+	pool, err := NewPoolFromEnv()
+	if err != nil {
+		t.Fatalf("NewPoolFromEnv() error: %v", err)
+	}
+	if len(pool.endpoints) != 2 || pool.endpoints[1].url.String() != "http://gpu-2:11434" {
+		t.Errorf("Unexpected endpoints: %+v", pool.Status())
+	}
+
+	t.Setenv("OLLAMA_URLS", "")
+	t.Setenv("OLLAMA_URL", "not a url")
+	if _, err := NewPoolFromEnv(); err == nil {
+		t.Error("Expected error for invalid URL")
+	}
+}
+
+func TestWatchdogGatesReadinessAndPulls(t *testing.T) {
+	t.Setenv("OLLAMA_MODEL_AUTO_PULL", "true")
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	m := metrics.NewModelMetrics("textanalyzer")
+
+	server := newFakeOllama(t, "host")
+	watchdog := NewModelWatchdog(server.URL, []string{"gemma3:4b"}, m)
+
+	watchdog.check(context.Background())
+	if watchdog.Ready() {
+		t.Fatal("Expected watchdog not ready while model is missing")
+	}
+
+	w := httptest.NewRecorder()
+	watchdog.ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while not ready, got %d", w.Code)
+	}
+
+	// The pull runs in the background; wait for it to be counted
+	deadline := time.Now().Add(2 * time.Second)
+	for testutil.ToFloat64(m.PullsTotal.WithLabelValues("gemma3:4b", "success")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for automatic pull")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	server.mu.Lock()
+	if len(server.pulled) != 1 || server.pulled[0] != "gemma3:4b" {
+		t.Errorf("Expected one pull of gemma3:4b, got %v", server.pulled)
+	}
+	server.mu.Unlock()
+
+	watchdog.check(context.Background())
+	if !watchdog.Ready() {
+		t.Errorf("Expected watchdog ready after pull, missing %v", watchdog.Missing())
+	}
+	if v := testutil.ToFloat64(m.Available.WithLabelValues("gemma3:4b")); v != 1 {
+		t.Errorf("Expected model available gauge 1, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.UnavailableSeconds.WithLabelValues("gemma3:4b")); v != 0 {
+		t.Errorf("Expected no unavailable time once the model is present, got %v", v)
+	}
+
+	w = httptest.NewRecorder()
+	watchdog.ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+	body, _ := io.ReadAll(w.Body)
+	if w.Code != http.StatusOK || !strings.Contains(string(body), `"ready":true`) {
+		t.Errorf("Expected 200 ready response, got %d %s", w.Code, body)
+	}
+}
+
+func TestWatchdogPullTimeout(t *testing.T) {
+	t.Setenv("OLLAMA_MODEL_PULL_TIMEOUT", "50ms")
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	watchdog := NewModelWatchdog(server.URL, []string{"gemma3:4b"}, nil)
+	err := watchdog.pull(context.Background(), "gemma3:4b")
+	if err == nil || !strings.Contains(err.Error(), "Timeout") {
+		t.Errorf("Expected pull to time out, got %v", err)
+	}
+}
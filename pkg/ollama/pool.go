@@ -0,0 +1,293 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoEndpoint is returned when no healthy endpoint serves the requested model
+var ErrNoEndpoint = errors.New("no healthy Ollama endpoint available")
+
+// endpoint is one Ollama host in a Pool
+type endpoint struct {
+	url      *url.URL
+	inFlight atomic.Int64
+
+	mu      sync.RWMutex
+	healthy bool
+	models  map[string]bool // nil until the first successful health check
+	lastErr error
+}
+
+// serves reports whether the endpoint can take a request for model. An endpoint whose
+// models aren't known yet is assumed to have them, so requests aren't refused at startup.
+func (e *endpoint) serves(model string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if !e.healthy {
+		return false
+	}
+	return model == "" || e.models == nil || hasModel(e.models, model)
+}
+
+func (e *endpoint) markDown(err error) {
+	e.mu.Lock()
+	e.healthy = false
+	e.lastErr = err
+	e.mu.Unlock()
+}
+
+// EndpointStatus describes an endpoint for admin and health output
+type EndpointStatus struct {
+	URL      string   `json:"url"`
+	Healthy  bool     `json:"healthy"`
+	InFlight int64    `json:"in_flight"`
+	Models   []string `json:"models"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Pool spreads Ollama requests across several hosts. It implements http.RoundTripper,
+// so an existing Ollama client uses it by setting it as its http.Client Transport and
+// pointing at any base URL: each request is sent to the least-loaded healthy endpoint
+// that has the request's model, and fails over to the next one on connection errors.
+type Pool struct {
+	endpoints []*endpoint
+	transport http.RoundTripper
+	interval  time.Duration
+	client    *http.Client
+}
+
+// NewPool creates a pool over the given base URLs. Endpoints start healthy with
+// unknown models, and take requests for any model, until the first health check.
+func NewPool(urls []string) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("at least one Ollama URL is required")
+	}
+
+	p := &Pool{
+		transport: http.DefaultTransport,
+		interval:  getEnvAsDuration("OLLAMA_HEALTH_CHECK_INTERVAL", 15*time.Second),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, raw := range urls {
+		u, err := url.Parse(strings.TrimSuffix(strings.TrimSpace(raw), "/"))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid Ollama URL %q", raw)
+		}
+		p.endpoints = append(p.endpoints, &endpoint{url: u, healthy: true})
+	}
+	return p, nil
+}
+
+// NewPoolFromEnv creates a pool from OLLAMA_URLS (comma-separated), falling back to OLLAMA_URL
+func NewPoolFromEnv() (*Pool, error) {
+	value := os.Getenv("OLLAMA_URLS")
+	if value == "" {
+		value = os.Getenv("OLLAMA_URL")
+	}
+
+	var urls []string
+	for _, u := range strings.Split(value, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return NewPool(urls)
+}
+
+// Start health checks every endpoint immediately and then every interval until ctx is cancelled
+func (p *Pool) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.checkAll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (p *Pool) checkAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range p.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			p.check(ctx, e)
+		}(e)
+	}
+	wg.Wait()
+}
+
+// check refreshes an endpoint's health and model list from /api/tags
+func (p *Pool) check(ctx context.Context, e *endpoint) {
+	models, err := listModels(ctx, p.client, e.url.String())
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		e.mu.Lock()
+		if e.healthy {
+			log.Printf("Warning: Ollama endpoint %s unhealthy: %v", e.url, err)
+		}
+		e.healthy, e.lastErr = false, err
+		e.mu.Unlock()
+		return
+	}
+
+	e.mu.Lock()
+	if !e.healthy {
+		log.Printf("Ollama endpoint %s healthy again", e.url)
+	}
+	e.healthy, e.models, e.lastErr = true, models, nil
+	e.mu.Unlock()
+}
+
+// Status returns the state of every endpoint
+func (p *Pool) Status() []EndpointStatus {
+	statuses := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		e.mu.RLock()
+		s := EndpointStatus{URL: e.url.String(), Healthy: e.healthy, InFlight: e.inFlight.Load(), Models: []string{}}
+		for m := range e.models {
+			s.Models = append(s.Models, m)
+		}
+		if e.lastErr != nil {
+			s.Error = e.lastErr.Error()
+		}
+		e.mu.RUnlock()
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// RoundTrip implements http.RoundTripper
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	model := requestModel(body)
+	if modelAgnostic[req.URL.Path] {
+		model = ""
+	}
+
+	tried := make(map[*endpoint]bool)
+	var lastErr error
+	for {
+		e := p.pick(model, tried)
+		if e == nil {
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w for model %q: %v", ErrNoEndpoint, model, lastErr)
+			}
+			return nil, fmt.Errorf("%w for model %q", ErrNoEndpoint, model)
+		}
+		tried[e] = true
+
+		resp, err := p.send(req, e, body)
+		if err == nil {
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+
+		log.Printf("Warning: Ollama endpoint %s failed, trying next: %v", e.url, err)
+		e.markDown(err)
+		lastErr = err
+	}
+}
+
+// send forwards the request to one endpoint, tracking it as in flight until the body is closed
+func (p *Pool) send(req *http.Request, e *endpoint, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.URL.Scheme = e.url.Scheme
+	out.URL.Host = e.url.Host
+	out.URL.Path = e.url.Path + req.URL.Path
+	out.Host = e.url.Host
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.ContentLength = int64(len(body))
+	}
+
+	e.inFlight.Add(1)
+	resp, err := p.transport.RoundTrip(out)
+	if err != nil {
+		e.inFlight.Add(-1)
+		return nil, err
+	}
+	resp.Body = &trackedBody{ReadCloser: resp.Body, endpoint: e}
+	return resp, nil
+}
+
+// pick returns the healthy, untried endpoint with the model and the fewest requests in flight
+func (p *Pool) pick(model string, tried map[*endpoint]bool) *endpoint {
+	var best *endpoint
+	for _, e := range p.endpoints {
+		if tried[e] || !e.serves(model) {
+			continue
+		}
+		if best == nil || e.inFlight.Load() < best.inFlight.Load() {
+			best = e
+		}
+	}
+	return best
+}
+
+// modelAgnostic are endpoints that name a model but don't need a host that already
+// has it: pulling downloads the model, and show must be able to report it missing
+var modelAgnostic = map[string]bool{
+	"/api/pull": true,
+	"/api/show": true,
+}
+
+// requestModel extracts the "model" field from an Ollama API request body
+func requestModel(body []byte) string {
+	var req struct {
+		Model string `json:"model"`
+		Name  string `json:"name"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &req) != nil {
+		return ""
+	}
+	if req.Model != "" {
+		return req.Model
+	}
+	return req.Name
+}
+
+// trackedBody decrements the endpoint's in-flight count once the response is consumed.
+// Generation streams for as long as the body is open, so that is when the host is busy.
+type trackedBody struct {
+	io.ReadCloser
+	endpoint *endpoint
+	once     sync.Once
+}
+
+func (b *trackedBody) Close() error {
+	b.once.Do(func() { b.endpoint.inFlight.Add(-1) })
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,180 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/docutag/platform/pkg/metrics"
+)
+
+// ErrInvalidOutput is returned when model output can't be decoded into the expected shape
+var ErrInvalidOutput = errors.New("invalid structured output from model")
+
+// Validator is implemented by output types with constraints beyond their JSON shape,
+// e.g. a score that must be between 0 and 1 or a tag list that must not be empty
+type Validator interface {
+	Validate() error
+}
+
+// Reprompt asks the model again with a correction instruction and returns its new output
+type Reprompt func(ctx context.Context, instruction string) (string, error)
+
+// StructuredDecoder decodes JSON model output for one pipeline stage. Output that
+// fails to parse or validate is repaired if possible, then re-prompted once, and
+// otherwise rejected with ErrInvalidOutput, so callers never store silent nulls.
+// Struct fields are required unless their JSON tag has omitempty.
+type StructuredDecoder struct {
+	Stage    string
+	Reprompt Reprompt                  // optional
+	Metrics  *metrics.LLMOutputMetrics // optional
+}
+
+// Decode decodes raw model output into v, which must be a non-nil pointer.
+// v is only modified when decoding and validation succeed.
+func (d StructuredDecoder) Decode(ctx context.Context, raw string, v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", v)
+	}
+
+	if err := decodeInto(raw, target); err == nil {
+		d.observe("valid")
+		return nil
+	}
+
+	repaired := repairJSON(raw)
+	err := decodeInto(repaired, target)
+	if err == nil {
+		d.observe("repaired")
+		return nil
+	}
+
+	if d.Reprompt != nil {
+		retry, promptErr := d.Reprompt(ctx, correctionInstruction(err, target.Type().Elem()))
+		if promptErr != nil {
+			d.observe("invalid")
+			return fmt.Errorf("%w (%s): %v; re-prompt failed: %v", ErrInvalidOutput, d.Stage, err, promptErr)
+		}
+		if err = decodeInto(repairJSON(retry), target); err == nil {
+			d.observe("reprompted")
+			return nil
+		}
+	}
+
+	d.observe("invalid")
+	return fmt.Errorf("%w (%s): %v", ErrInvalidOutput, d.Stage, err)
+}
+
+func (d StructuredDecoder) observe(outcome string) {
+	if d.Metrics != nil {
+		d.Metrics.ObserveOutput(d.Stage, outcome)
+	}
+}
+
+// decodeInto decodes into a fresh value and copies it to target only if it validates
+func decodeInto(raw string, target reflect.Value) error {
+	fresh := reflect.New(target.Type().Elem())
+
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(fresh.Interface()); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if dec.More() {
+		return errors.New("unexpected content after JSON value")
+	}
+	if err := checkRequired(raw, fresh.Elem().Type()); err != nil {
+		return err
+	}
+
+	if validator, ok := fresh.Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return fmt.Errorf("output failed validation: %w", err)
+		}
+	}
+
+	target.Elem().Set(fresh.Elem())
+	return nil
+}
+
+// checkRequired rejects a JSON object that is missing, or has null for, any field of
+// struct type t whose tag lacks omitempty; decoding alone would leave those as zero values
+func checkRequired(raw string, t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+		return fmt.Errorf("failed to parse JSON object: %w", err)
+	}
+	present := make(map[string]bool, len(fields))
+	for key, value := range fields {
+		if string(value) != "null" {
+			present[strings.ToLower(key)] = true
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" || strings.Contains(","+opts+",", ",omitempty,") {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if !present[strings.ToLower(name)] {
+			return fmt.Errorf("missing required field %q", name)
+		}
+	}
+	return nil
+}
+
+var (
+	codeFence     = regexp.MustCompile("(?s)```(?:json|JSON)?\\s*(.*?)\\s*```")
+	trailingComma = regexp.MustCompile(`,\s*([}\]])`)
+)
+
+// repairJSON fixes the usual ways models wrap or break JSON: markdown code fences,
+// prose before or after the value, trailing commas and typographic quotes
+func repairJSON(raw string) string {
+	s := strings.TrimSpace(raw)
+	if m := codeFence.FindStringSubmatch(s); m != nil {
+		s = m[1]
+	}
+
+	start := strings.IndexAny(s, "{[")
+	if start >= 0 {
+		closer := byte('}')
+		if s[start] == '[' {
+			closer = ']'
+		}
+		if end := strings.LastIndexByte(s, closer); end > start {
+			s = s[start : end+1]
+		}
+	}
+
+	s = strings.NewReplacer("“", `"`, "”", `"`).Replace(s)
+	return trailingComma.ReplaceAllString(s, "$1")
+}
+
+// correctionInstruction tells the model what was wrong and shows the expected shape
+func correctionInstruction(err error, t reflect.Type) string {
+	var shape bytes.Buffer
+	example, _ := json.Marshal(reflect.New(t).Interface())
+	json.Indent(&shape, example, "", "  ")
+
+	return fmt.Sprintf("Your previous response could not be used: %v.\n"+
+		"Return only valid JSON matching this structure, with no explanation and no code fences:\n%s",
+		err, shape.String())
+}
@@ -0,0 +1,123 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/docutag/platform/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type scoreOutput struct {
+	Score  float64  `json:"score"`
+	Reason string   `json:"reason"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+func (o *scoreOutput) Validate() error {
+	if o.Score < 0 || o.Score > 1 {
+		return errors.New("score must be between 0 and 1")
+	}
+	return nil
+}
+
+func TestStructuredDecoderRepairs(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"code fence", "```json\n{\"score\": 0.8, \"reason\": \"ok\"}\n```"},
+		{"surrounding prose", "Here is the result: {\"score\": 0.8, \"reason\": \"ok\"} Hope this helps!"},
+		{"trailing comma", `{"score": 0.8, "reason": "ok", "tags": ["a", "b",],}`},
+		{"smart quotes", "{“score”: 0.8, “reason”: “ok”}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out scoreOutput
+			if err := (StructuredDecoder{Stage: "scoring"}).Decode(context.Background(), tt.raw, &out); err != nil {
+				t.Fatalf("Decode() error: %v", err)
+			}
+			if out.Score != 0.8 || out.Reason != "ok" {
+				t.Errorf("Unexpected output: %+v", out)
+			}
+		})
+	}
+}
+
+func TestStructuredDecoderReprompts(t *testing.T) {
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	m := metrics.NewLLMOutputMetrics("textanalyzer")
+
+	var instruction string
+	decoder := StructuredDecoder{
+		Stage:   "scoring",
+		Metrics: m,
+		Reprompt: func(ctx context.Context, prompt string) (string, error) {
+			instruction = prompt
+			return `{"score": 0.3, "reason": "fixed"}`, nil
+		},
+	}
+
+	var out scoreOutput
+	if err := decoder.Decode(context.Background(), `{"score": 7, "reason": "out of range"}`, &out); err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if out.Score != 0.3 {
+		t.Errorf("Expected re-prompted output, got %+v", out)
+	}
+	if !strings.Contains(instruction, "score must be between 0 and 1") || !strings.Contains(instruction, `"reason"`) {
+		t.Errorf("Expected instruction to include the error and expected shape, got:\n%s", instruction)
+	}
+	if v := testutil.ToFloat64(m.OutputsTotal.WithLabelValues("scoring", "reprompted")); v != 1 {
+		t.Errorf("Expected 1 reprompted output, got %v", v)
+	}
+}
+
+func TestStructuredDecoderRejects(t *testing.T) {
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	m := metrics.NewLLMOutputMetrics("textanalyzer")
+
+	out := scoreOutput{Score: 0.5, Reason: "previous"}
+	err := (StructuredDecoder{Stage: "tags", Metrics: m}).Decode(context.Background(), "I cannot help with that.", &out)
+	if !errors.Is(err, ErrInvalidOutput) {
+		t.Fatalf("Expected ErrInvalidOutput, got %v", err)
+	}
+	if out.Reason != "previous" {
+		t.Errorf("Expected target to be left untouched, got %+v", out)
+	}
+	if v := testutil.ToFloat64(m.OutputsTotal.WithLabelValues("tags", "invalid")); v != 1 {
+		t.Errorf("Expected 1 invalid output, got %v", v)
+	}
+}
+
+func TestStructuredDecoderRequiresFields(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"empty object", `{}`},
+		{"missing field", `{"score": 0.8}`},
+		{"null field", `{"score": 0.8, "reason": null}`},
+		{"null object", `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out scoreOutput
+			err := (StructuredDecoder{Stage: "scoring"}).Decode(context.Background(), tt.raw, &out)
+			if !errors.Is(err, ErrInvalidOutput) {
+				t.Fatalf("Expected ErrInvalidOutput, got %v", err)
+			}
+		})
+	}
+
+	// Fields tagged omitempty may be left out
+	var out scoreOutput
+	if err := (StructuredDecoder{Stage: "scoring"}).Decode(context.Background(), `{"score": 0.8, "reason": "ok"}`, &out); err != nil {
+		t.Errorf("Decode() error: %v", err)
+	}
+}
@@ -0,0 +1,283 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docutag/platform/pkg/metrics"
+)
+
+// ModelWatchdog periodically checks that the Ollama models a service needs are
+// present, optionally pulls missing ones, and gates readiness until they are
+type ModelWatchdog struct {
+	baseURL    string
+	models     []string
+	interval   time.Duration
+	autoPull   bool
+	httpClient *http.Client
+	pullClient *http.Client
+	metrics    *metrics.ModelMetrics
+
+	mu        sync.RWMutex
+	missing   []string
+	lastErr   error
+	checked   bool
+	lastCheck time.Time
+	pulling   map[string]bool
+}
+
+// NewModelWatchdog creates a watchdog for the given models. Metrics may be nil.
+// OLLAMA_MODEL_CHECK_INTERVAL (default 30s) and OLLAMA_MODEL_AUTO_PULL (default false)
+// control how often models are checked and whether missing ones are pulled;
+// OLLAMA_MODEL_PULL_TIMEOUT (default 30m) bounds a single pull.
+func NewModelWatchdog(baseURL string, models []string, m *metrics.ModelMetrics) *ModelWatchdog {
+	return &ModelWatchdog{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		models:     models,
+		interval:   getEnvAsDuration("OLLAMA_MODEL_CHECK_INTERVAL", 30*time.Second),
+		autoPull:   getEnvAsBool("OLLAMA_MODEL_AUTO_PULL", false),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		pullClient: &http.Client{Timeout: getEnvAsDuration("OLLAMA_MODEL_PULL_TIMEOUT", 30*time.Minute)},
+		metrics:    m,
+		pulling:    make(map[string]bool),
+	}
+}
+
+// Start checks models immediately and then every interval until ctx is cancelled
+func (w *ModelWatchdog) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			w.check(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Ready reports whether the last check found every required model
+func (w *ModelWatchdog) Ready() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.checked && w.lastErr == nil && len(w.missing) == 0
+}
+
+// Missing returns the models missing at the last check
+func (w *ModelWatchdog) Missing() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return append([]string(nil), w.missing...)
+}
+
+// ServeHTTP reports model readiness, for use from a service's /ready endpoint.
+// Responds 503 until every required model is present.
+func (w *ModelWatchdog) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	ready := w.Ready()
+
+	w.mu.RLock()
+	status := map[string]interface{}{
+		"ready":   ready,
+		"models":  w.models,
+		"missing": w.missing,
+	}
+	if w.lastErr != nil {
+		status["error"] = w.lastErr.Error()
+	}
+	w.mu.RUnlock()
+
+	rw.Header().Set("Content-Type", "application/json")
+	if !ready {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(rw).Encode(status)
+}
+
+// check lists the installed models, updates readiness and metrics, and starts pulls
+func (w *ModelWatchdog) check(ctx context.Context) {
+	installed, err := listModels(ctx, w.httpClient, w.baseURL)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	var missing []string
+	for _, model := range w.models {
+		if err != nil || !hasModel(installed, model) {
+			missing = append(missing, model)
+		}
+	}
+
+	now := time.Now()
+	w.mu.Lock()
+	elapsed := now.Sub(w.lastCheck)
+	wasChecked := w.checked
+	w.missing, w.lastErr, w.checked, w.lastCheck = missing, err, true, now
+	w.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Warning: Ollama model check failed: %v", err)
+	} else if len(missing) > 0 {
+		log.Printf("Warning: required Ollama models missing: %s", strings.Join(missing, ", "))
+	}
+
+	if w.metrics != nil {
+		for _, model := range w.models {
+			w.metrics.Available.WithLabelValues(model).Set(1)
+		}
+		for _, model := range missing {
+			w.metrics.Available.WithLabelValues(model).Set(0)
+			if wasChecked {
+				w.metrics.UnavailableSeconds.WithLabelValues(model).Add(elapsed.Seconds())
+			}
+		}
+	}
+
+	// Only pull when Ollama answered; an unreachable server can't pull either
+	if w.autoPull && err == nil {
+		for _, model := range missing {
+			w.startPull(ctx, model)
+		}
+	}
+}
+
+// listModels returns the names of models an Ollama server reports via /api/tags
+func listModels(ctx context.Context, client *http.Client, baseURL string) (map[string]bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to list models: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %w", err)
+	}
+
+	installed := make(map[string]bool, len(tags.Models))
+	for _, m := range tags.Models {
+		installed[m.Name] = true
+	}
+	return installed, nil
+}
+
+// hasModel reports whether a model is installed; an untagged name matches its :latest tag
+func hasModel(installed map[string]bool, model string) bool {
+	if installed[model] {
+		return true
+	}
+	return !strings.Contains(model, ":") && installed[model+":latest"]
+}
+
+// startPull pulls a model in the background unless a pull for it is already running.
+// Pulls can take many minutes, so they use pullClient rather than the check's short timeout.
+func (w *ModelWatchdog) startPull(ctx context.Context, model string) {
+	w.mu.Lock()
+	if w.pulling[model] {
+		w.mu.Unlock()
+		return
+	}
+	w.pulling[model] = true
+	w.mu.Unlock()
+
+	go func() {
+		defer func() {
+			w.mu.Lock()
+			delete(w.pulling, model)
+			w.mu.Unlock()
+		}()
+
+		log.Printf("Pulling missing Ollama model %s", model)
+		status := "success"
+		if err := w.pull(ctx, model); err != nil {
+			status = "error"
+			if ctx.Err() == nil {
+				log.Printf("Warning: failed to pull Ollama model %s: %v", model, err)
+			}
+		} else {
+			log.Printf("Pulled Ollama model %s", model)
+		}
+
+		if w.metrics != nil {
+			w.metrics.PullsTotal.WithLabelValues(model, status).Inc()
+		}
+	}()
+}
+
+// pull asks Ollama to download a model and waits for it to finish
+func (w *ModelWatchdog) pull(ctx context.Context, model string) error {
+	body, err := json.Marshal(map[string]interface{}{"model": model, "stream": false})
+	if err != nil {
+		return fmt.Errorf("failed to encode pull request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.baseURL+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.pullClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to pull model: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to pull model: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &result); err == nil && result.Error != "" {
+		return fmt.Errorf("failed to pull model: %s", result.Error)
+	}
+	return nil
+}
+
+func getEnvAsDuration(key string, defaultVal time.Duration) time.Duration {
+	valueStr := os.Getenv(key)
+	if value, err := time.ParseDuration(valueStr); err == nil {
+		return value
+	}
+	return defaultVal
+}
+
+func getEnvAsBool(key string, defaultVal bool) bool {
+	valueStr := os.Getenv(key)
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultVal
+}
@@ -0,0 +1,303 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docutag/platform/pkg/auth"
+	"github.com/redis/go-redis/v9"
+)
+
+// Limit is a token bucket: Rate tokens per second, holding at most Burst tokens.
+// A zero Rate disables the limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute returns a limit of n requests per minute with the given burst
+func PerMinute(n, burst int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: burst}
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// tokenBucket atomically refills every bucket and takes one token from each of them,
+// or from none if any bucket is empty, so a request denied by one limit doesn't use
+// up another. KEYS are the bucket keys; ARGV is a clock override (ms, 0 for the Redis
+// server clock) followed by a rate (tokens/s) and burst per key. Using the server clock
+// keeps replicas with skewed clocks from minting or burning tokens in shared buckets.
+// Returns {allowed, lowest remaining tokens, retry after (ms)}.
+var tokenBucket = redis.NewScript(`
+local now = tonumber(ARGV[1])
+if now == 0 then
+  local time = redis.call("TIME")
+  now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+end
+local tokens = {}
+local allowed = 1
+local retry = 0
+
+for i = 1, #KEYS do
+  local rate = tonumber(ARGV[2 * i])
+  local burst = tonumber(ARGV[2 * i + 1])
+  local bucket = redis.call("HMGET", KEYS[i], "tokens", "ts")
+  local t = tonumber(bucket[1])
+  local ts = tonumber(bucket[2])
+  if t == nil then
+    t = burst
+    ts = now
+  end
+
+  t = math.min(burst, t + math.max(0, now - ts) / 1000 * rate)
+  if t < 1 then
+    allowed = 0
+    retry = math.max(retry, math.ceil((1 - t) / rate * 1000))
+  end
+  tokens[i] = t
+end
+
+local remaining = nil
+for i = 1, #KEYS do
+  local rate = tonumber(ARGV[2 * i])
+  local burst = tonumber(ARGV[2 * i + 1])
+  if allowed == 1 then
+    tokens[i] = tokens[i] - 1
+  end
+  if remaining == nil or tokens[i] < remaining then
+    remaining = tokens[i]
+  end
+
+  redis.call("HSET", KEYS[i], "tokens", tostring(tokens[i]), "ts", now)
+  redis.call("PEXPIRE", KEYS[i], math.ceil(burst / rate * 1000) + 1000)
+end
+
+return {allowed, math.floor(remaining), retry}
+`)
+
+// Limiter enforces token bucket limits shared by all replicas through Redis
+type Limiter struct {
+	client *redis.Client
+	prefix string
+	now    func() time.Time // overrides the Redis server clock in tests
+}
+
+// NewLimiter creates a limiter storing buckets under the given key prefix
+func NewLimiter(client *redis.Client, prefix string) *Limiter {
+	return &Limiter{client: client, prefix: prefix}
+}
+
+// Bucket is one token bucket checked by AllowAll
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// Allow takes one token from the bucket for key
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return l.AllowAll(ctx, Bucket{Key: key, Limit: limit})
+}
+
+// AllowAll takes one token from each bucket if all of them have one, and none otherwise.
+// Buckets with a zero Rate are ignored.
+func (l *Limiter) AllowAll(ctx context.Context, buckets ...Bucket) (Result, error) {
+	var keys []string
+	var now int64
+	if l.now != nil {
+		now = l.now().UnixMilli()
+	}
+	args := []interface{}{now}
+	remaining := math.MaxInt
+	for _, b := range buckets {
+		if b.Limit.Rate <= 0 {
+			remaining = min(remaining, b.Limit.Burst)
+			continue
+		}
+		keys = append(keys, l.prefix+b.Key)
+		args = append(args, b.Limit.Rate, max(b.Limit.Burst, 1))
+	}
+	if len(keys) == 0 {
+		return Result{Allowed: true, Remaining: remaining}, nil
+	}
+
+	values, err := tokenBucket.Run(ctx, l.client, keys, args...).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run token bucket: %w", err)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
+
+// Config configures the rate limiting middleware
+type Config struct {
+	// PerClient limits each client, identified by ClientKey
+	PerClient Limit
+	// Global limits all clients together
+	Global Limit
+	// Paths are the path prefixes that are rate limited
+	Paths []string
+	// TrustedProxies are the networks whose X-Forwarded-For entries are believed
+	TrustedProxies []netip.Prefix
+	// ClientKey identifies the client; defaults to ClientKeyFunc(TrustedProxies)
+	ClientKey func(r *http.Request) string
+}
+
+// ConfigFromEnv builds a config for the given paths from RATE_LIMIT_CLIENT_RPM (default 60),
+// RATE_LIMIT_CLIENT_BURST (default 10), RATE_LIMIT_GLOBAL_RPM (default 0, off),
+// RATE_LIMIT_GLOBAL_BURST (default 50) and RATE_LIMIT_TRUSTED_PROXIES (comma-separated
+// CIDRs or addresses of load balancers in front of the service, default none)
+func ConfigFromEnv(paths ...string) Config {
+	return Config{
+		PerClient:      PerMinute(getEnvAsInt("RATE_LIMIT_CLIENT_RPM", 60), getEnvAsInt("RATE_LIMIT_CLIENT_BURST", 10)),
+		Global:         PerMinute(getEnvAsInt("RATE_LIMIT_GLOBAL_RPM", 0), getEnvAsInt("RATE_LIMIT_GLOBAL_BURST", 50)),
+		Paths:          paths,
+		TrustedProxies: ParseTrustedProxies(os.Getenv("RATE_LIMIT_TRUSTED_PROXIES")),
+	}
+}
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs or single addresses,
+// skipping invalid entries with a warning
+func ParseTrustedProxies(value string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		log.Printf("Warning: ignoring invalid trusted proxy %q", entry)
+	}
+	return prefixes
+}
+
+// DefaultClientKey identifies a client by its authenticated API key, falling back to the
+// remote address. X-Forwarded-For is ignored; use ClientKeyFunc behind a load balancer.
+func DefaultClientKey(r *http.Request) string {
+	return ClientKeyFunc(nil)(r)
+}
+
+// ClientKeyFunc identifies a client by the API key that auth.Middleware authenticated, so
+// it must run after authentication. Anonymous clients are identified by IP: the remote
+// address, or if that is a trusted proxy, the rightmost X-Forwarded-For hop that isn't.
+// Hops left of that are set by the client and can't be used to pick a fresh bucket.
+func ClientKeyFunc(trustedProxies []netip.Prefix) func(r *http.Request) string {
+	trusted := func(addr netip.Addr) bool {
+		for _, prefix := range trustedProxies {
+			if prefix.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(r *http.Request) string {
+		if key, ok := auth.KeyFromContext(r.Context()); ok {
+			return "key:" + key.ID
+		}
+
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		addr, err := netip.ParseAddr(host)
+		if err != nil || !trusted(addr) {
+			return "ip:" + host
+		}
+
+		hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			addr = hop
+			if !trusted(hop) {
+				break
+			}
+		}
+		return "ip:" + addr.Unmap().String()
+	}
+}
+
+// Middleware rejects requests over the per-client or global limit with 429 and Retry-After.
+// If Redis is unavailable requests are allowed, so an outage doesn't take the API down.
+func Middleware(limiter *Limiter, cfg Config) func(http.Handler) http.Handler {
+	clientKey := cfg.ClientKey
+	if clientKey == nil {
+		clientKey = ClientKeyFunc(cfg.TrustedProxies)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !matches(r.URL.Path, cfg.Paths) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := limiter.AllowAll(r.Context(),
+				Bucket{Key: "client:" + clientKey(r), Limit: cfg.PerClient},
+				Bucket{Key: "global", Limit: cfg.Global})
+			if err != nil {
+				log.Printf("Warning: rate limiter unavailable, allowing request: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !result.Allowed {
+				retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprintf(w, `{"error":"rate limit exceeded","retry_after_seconds":%d}`, retryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func matches(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func getEnvAsInt(key string, defaultVal int) int {
+	valueStr := os.Getenv(key)
+	if value, err := strconv.Atoi(valueStr); err == nil {
+		return value
+	}
+	return defaultVal
+}
@@ -0,0 +1,203 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/docutag/platform/pkg/auth"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestLimiter returns a limiter backed by an in-process Redis and a controllable clock
+func newTestLimiter(t *testing.T) (*Limiter, *miniredis.Miniredis, *time.Time) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	now := time.Unix(1700000000, 0)
+	limiter := NewLimiter(client, "ratelimit:")
+	limiter.now = func() time.Time { return now }
+	return limiter, mr, &now
+}
+
+func TestAllowTokenBucket(t *testing.T) {
+	limiter, _, now := newTestLimiter(t)
+	ctx := context.Background()
+	limit := PerMinute(60, 3) // one token per second, burst of three
+
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(ctx, "client", limit)
+		if err != nil {
+			t.Fatalf("Allow() error: %v", err)
+		}
+		if !result.Allowed {
+			t.Fatalf("Expected request %d within burst to be allowed", i+1)
+		}
+	}
+
+	result, _ := limiter.Allow(ctx, "client", limit)
+	if result.Allowed {
+		t.Fatal("Expected request over burst to be rejected")
+	}
+	if result.RetryAfter != time.Second {
+		t.Errorf("Expected retry after 1s, got %v", result.RetryAfter)
+	}
+
+	// Other clients have their own bucket
+	if result, _ := limiter.Allow(ctx, "other", limit); !result.Allowed {
+		t.Error("Expected a different client to be allowed")
+	}
+
+	*now = now.Add(1500 * time.Millisecond)
+	if result, _ := limiter.Allow(ctx, "client", limit); !result.Allowed {
+		t.Error("Expected a token to be refilled after 1.5s")
+	}
+	if result, _ := limiter.Allow(ctx, "client", limit); result.Allowed {
+		t.Error("Expected only one token to be refilled")
+	}
+}
+
+func TestAllowUsesRedisClock(t *testing.T) {
+	limiter, mr, _ := newTestLimiter(t)
+	limiter.now = nil
+	ctx := context.Background()
+	limit := PerMinute(60, 1)
+
+	mr.SetTime(time.Unix(1700000000, 0))
+	if result, _ := limiter.Allow(ctx, "client", limit); !result.Allowed {
+		t.Fatal("Expected first request to be allowed")
+	}
+	if result, _ := limiter.Allow(ctx, "client", limit); result.Allowed {
+		t.Fatal("Expected request over burst to be rejected")
+	}
+
+	mr.SetTime(time.Unix(1700000001, 0))
+	if result, _ := limiter.Allow(ctx, "client", limit); !result.Allowed {
+		t.Error("Expected a token to be refilled after the Redis clock advanced 1s")
+	}
+}
+
+func TestAllowDisabledLimit(t *testing.T) {
+	limiter, _, _ := newTestLimiter(t)
+
+	for i := 0; i < 100; i++ {
+		if result, err := limiter.Allow(context.Background(), "client", Limit{}); err != nil || !result.Allowed {
+			t.Fatalf("Expected zero-rate limit to allow everything, got %+v, %v", result, err)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	limiter, mr, _ := newTestLimiter(t)
+	cfg := Config{
+		PerClient: PerMinute(60, 1),
+		Global:    PerMinute(60, 2),
+		Paths:     []string{"/api/scrape", "/api/analyze"},
+	}
+	handler := Middleware(limiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, nil)
+		req.RemoteAddr = ip + ":41234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("/api/scrape", "203.0.113.1"); w.Code != http.StatusOK {
+		t.Fatalf("Expected first request to pass, got %d", w.Code)
+	}
+
+	w := send("/api/scrape", "203.0.113.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected per-client limit to return 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected Retry-After: 1, got %q", w.Header().Get("Retry-After"))
+	}
+
+	if w := send("/api/analyze", "203.0.113.2"); w.Code != http.StatusOK {
+		t.Errorf("Expected second client to pass, got %d", w.Code)
+	}
+	if w := send("/api/analyze", "203.0.113.3"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected global limit to return 429, got %d", w.Code)
+	}
+
+	// Unlimited paths are never counted
+	if w := send("/api/requests", "203.0.113.1"); w.Code != http.StatusOK {
+		t.Errorf("Expected unlimited path to pass, got %d", w.Code)
+	}
+
+	// Fail open when Redis is down
+	mr.Close()
+	if w := send("/api/scrape", "203.0.113.1"); w.Code != http.StatusOK {
+		t.Errorf("Expected request to pass with Redis down, got %d", w.Code)
+	}
+}
+
+func TestAllowAllTakesNothingWhenDenied(t *testing.T) {
+	limiter, _, _ := newTestLimiter(t)
+	ctx := context.Background()
+	client := Bucket{Key: "client:a", Limit: PerMinute(60, 1)}
+	global := Bucket{Key: "global", Limit: PerMinute(60, 1)}
+
+	// Drain the global bucket through another client
+	if result, _ := limiter.AllowAll(ctx, Bucket{Key: "client:b", Limit: client.Limit}, global); !result.Allowed {
+		t.Fatal("Expected first request to be allowed")
+	}
+
+	if result, _ := limiter.AllowAll(ctx, client, global); result.Allowed {
+		t.Fatal("Expected request to be denied by the global limit")
+	}
+
+	// The denied request must not have spent client a's only token
+	if result, _ := limiter.Allow(ctx, client.Key, client.Limit); !result.Allowed {
+		t.Error("Expected client token to be left after a global denial")
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/scrape", nil)
+	req.RemoteAddr = "10.0.0.5:41234"
+	req.Header.Set("Authorization", "Bearer unvalidated")
+	req.Header.Set("X-Forwarded-For", "198.51.100.9, 203.0.113.7")
+	if got := DefaultClientKey(req); got != "ip:10.0.0.5" {
+		t.Errorf("Expected remote IP key ignoring headers, got %q", got)
+	}
+
+	// Behind a trusted load balancer, the rightmost untrusted hop is the client;
+	// 198.51.100.9 was supplied by the client and is ignored
+	trusted := ParseTrustedProxies("10.0.0.0/8, 192.0.2.10, not-an-ip")
+	if len(trusted) != 2 {
+		t.Fatalf("Expected 2 trusted proxies, got %v", trusted)
+	}
+	req.Header.Set("X-Forwarded-For", "198.51.100.9, 203.0.113.7, 192.0.2.10")
+	if got := ClientKeyFunc(trusted)(req); got != "ip:203.0.113.7" {
+		t.Errorf("Expected rightmost untrusted hop, got %q", got)
+	}
+
+	req.RemoteAddr = "203.0.113.50:41234"
+	if got := ClientKeyFunc(trusted)(req); got != "ip:203.0.113.50" {
+		t.Errorf("Expected forwarded header ignored from untrusted peer, got %q", got)
+	}
+
+	// Authenticated requests are keyed by API key ID
+	store, _ := auth.ParseStaticKeys("ingest:s3cret")
+	var got string
+	handler := auth.Middleware(auth.Config{Store: store})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = DefaultClientKey(r)
+	}))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got != "key:ingest" {
+		t.Errorf("Expected API key ID, got %q", got)
+	}
+}
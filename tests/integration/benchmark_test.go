@@ -5,8 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -21,16 +27,77 @@ const (
 
 // BenchmarkResult holds benchmark statistics
 type BenchmarkResult struct {
-	TotalRequests     int64
-	SuccessfulReqs    int64
-	FailedReqs        int64
-	TotalDuration     time.Duration
-	AvgResponseTime   time.Duration
-	MinResponseTime   time.Duration
-	MaxResponseTime   time.Duration
-	RequestsPerSecond float64
+	TotalRequests     int64         `json:"total_requests"`
+	SuccessfulReqs    int64         `json:"successful_requests"`
+	FailedReqs        int64         `json:"failed_requests"`
+	TotalDuration     time.Duration `json:"total_duration_ns"`
+	AvgResponseTime   time.Duration `json:"avg_response_time_ns"`
+	MinResponseTime   time.Duration `json:"min_response_time_ns"`
+	MaxResponseTime   time.Duration `json:"max_response_time_ns"`
+	P50ResponseTime   time.Duration `json:"p50_response_time_ns"`
+	P95ResponseTime   time.Duration `json:"p95_response_time_ns"`
+	P99ResponseTime   time.Duration `json:"p99_response_time_ns"`
+	RequestsPerSecond float64       `json:"requests_per_second"`
+
+	// durations holds individual response times for percentile calculation
+	// when results from several load stages are merged
+	durations []time.Duration
 }
 
+// LoadStage is one phase of a load profile
+type LoadStage struct {
+	Requests    int
+	Concurrency int
+}
+
+// LoadProfile is a named sequence of load stages
+type LoadProfile struct {
+	Name   string
+	Stages []LoadStage
+}
+
+// loadProfiles are the named profiles selectable via BENCHMARK_PROFILE
+var loadProfiles = map[string]LoadProfile{
+	// ramp-up gradually increases concurrency to find where latency degrades
+	"ramp-up": {
+		Name: "ramp-up",
+		Stages: []LoadStage{
+			{Requests: 10, Concurrency: 1},
+			{Requests: 20, Concurrency: 2},
+			{Requests: 40, Concurrency: 4},
+			{Requests: 80, Concurrency: 8},
+		},
+	},
+	// sustained holds a constant moderate load
+	"sustained": {
+		Name: "sustained",
+		Stages: []LoadStage{
+			{Requests: 100, Concurrency: 4},
+		},
+	},
+	// spike bursts from a quiet baseline to high concurrency and back
+	"spike": {
+		Name: "spike",
+		Stages: []LoadStage{
+			{Requests: 10, Concurrency: 2},
+			{Requests: 80, Concurrency: 16},
+			{Requests: 10, Concurrency: 2},
+		},
+	},
+}
+
+// BenchmarkReport is the JSON document exported for trend tracking across commits
+type BenchmarkReport struct {
+	Commit    string                     `json:"commit"`
+	Timestamp time.Time                  `json:"timestamp"`
+	Results   map[string]BenchmarkResult `json:"results"`
+}
+
+var (
+	benchmarkResultsMu sync.Mutex
+	benchmarkResults   = make(map[string]BenchmarkResult)
+)
+
 // TestBenchmarkControllerLoad runs load tests on the controller
 func TestBenchmarkControllerLoad(t *testing.T) {
 	if testing.Short() {
@@ -59,6 +126,16 @@ func TestBenchmarkControllerLoad(t *testing.T) {
 	t.Run("BenchmarkMixedWorkload", func(t *testing.T) {
 		benchmarkMixedWorkload(t, 25, 3) // 25 of each type, 3 concurrent workers
 	})
+
+	for _, profile := range selectedLoadProfiles(t) {
+		profile := profile
+		t.Run("BenchmarkProfile_"+profile.Name, func(t *testing.T) {
+			benchmarkLoadProfile(t, profile)
+		})
+	}
+
+	exportBenchmarkResults(t)
+	compareWithBaseline(t)
 }
 
 // setupBenchmarkServices builds and starts all services for benchmarking
@@ -216,6 +293,132 @@ func benchmarkMixedWorkload(t *testing.T, requestsPerType, concurrency int) {
 	printBenchmarkResults(t, "Mixed Workload (50% analyze, 50% scrape)", result)
 }
 
+// selectedLoadProfiles returns the profiles named in BENCHMARK_PROFILE
+// (comma-separated, or "all"). No profiles run when it is unset.
+func selectedLoadProfiles(t *testing.T) []LoadProfile {
+	names := getEnv("BENCHMARK_PROFILE")
+	if names == "" {
+		return nil
+	}
+
+	if names == "all" {
+		names = "ramp-up,sustained,spike"
+	}
+
+	var profiles []LoadProfile
+	for _, name := range strings.Split(names, ",") {
+		profile, ok := loadProfiles[strings.TrimSpace(name)]
+		if !ok {
+			t.Fatalf("Unknown load profile %q (available: ramp-up, sustained, spike, all)", name)
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles
+}
+
+// benchmarkLoadProfile runs each stage of a load profile against the /analyze
+// endpoint and reports latency percentiles for the profile as a whole
+func benchmarkLoadProfile(t *testing.T, profile LoadProfile) {
+	t.Logf("Running load profile %q with %d stages", profile.Name, len(profile.Stages))
+
+	var stageResults []BenchmarkResult
+	offset := 0
+	for i, stage := range profile.Stages {
+		t.Logf("Stage %d/%d: %d requests with %d concurrent workers", i+1, len(profile.Stages), stage.Requests, stage.Concurrency)
+
+		base := offset
+		result := runLoadTest(t, stage.Requests, stage.Concurrency, func(i int) (*http.Response, error) {
+			reqBody := map[string]interface{}{
+				"text": fmt.Sprintf("Profile %s request %d: sample text for load profile benchmarking", profile.Name, base+i),
+			}
+
+			body, err := json.Marshal(reqBody)
+			if err != nil {
+				return nil, err
+			}
+
+			client := &http.Client{Timeout: 60 * time.Second}
+			return client.Post(benchControllerURL+"/api/analyze", "application/json", bytes.NewReader(body))
+		})
+		stageResults = append(stageResults, result)
+		offset += stage.Requests
+	}
+
+	printBenchmarkResults(t, "Load Profile: "+profile.Name, mergeBenchmarkResults(stageResults))
+}
+
+// mergeBenchmarkResults combines stage results into a single result,
+// recomputing percentiles over all individual response times
+func mergeBenchmarkResults(results []BenchmarkResult) BenchmarkResult {
+	var merged BenchmarkResult
+	var totalResponseTime time.Duration
+
+	for _, r := range results {
+		merged.TotalRequests += r.TotalRequests
+		merged.SuccessfulReqs += r.SuccessfulReqs
+		merged.FailedReqs += r.FailedReqs
+		merged.TotalDuration += r.TotalDuration
+		merged.durations = append(merged.durations, r.durations...)
+		totalResponseTime += r.AvgResponseTime * time.Duration(r.SuccessfulReqs)
+
+		if merged.MinResponseTime == 0 || r.MinResponseTime < merged.MinResponseTime {
+			merged.MinResponseTime = r.MinResponseTime
+		}
+		if r.MaxResponseTime > merged.MaxResponseTime {
+			merged.MaxResponseTime = r.MaxResponseTime
+		}
+	}
+
+	if merged.SuccessfulReqs > 0 {
+		merged.AvgResponseTime = totalResponseTime / time.Duration(merged.SuccessfulReqs)
+	}
+	if merged.TotalDuration > 0 {
+		merged.RequestsPerSecond = float64(merged.SuccessfulReqs) / merged.TotalDuration.Seconds()
+	}
+
+	merged.P50ResponseTime, merged.P95ResponseTime, merged.P99ResponseTime = latencyPercentiles(merged.durations)
+	return merged
+}
+
+// latencyPercentiles returns the p50, p95 and p99 of the given durations
+func latencyPercentiles(durations []time.Duration) (p50, p95, p99 time.Duration) {
+	if len(durations) == 0 {
+		return 0, 0, 0
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return percentile(sorted, 50), percentile(sorted, 95), percentile(sorted, 99)
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// TestLatencyPercentiles verifies nearest-rank percentile calculation
+func TestLatencyPercentiles(t *testing.T) {
+	durations := make([]time.Duration, 0, 100)
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	p50, p95, p99 := latencyPercentiles(durations)
+	if p50 != 50*time.Millisecond || p95 != 95*time.Millisecond || p99 != 99*time.Millisecond {
+		t.Errorf("Expected p50/p95/p99 of 50ms/95ms/99ms, got %v/%v/%v", p50, p95, p99)
+	}
+
+	if p50, p95, p99 := latencyPercentiles(nil); p50 != 0 || p95 != 0 || p99 != 0 {
+		t.Errorf("Expected zero percentiles for no samples, got %v/%v/%v", p50, p95, p99)
+	}
+}
+
 // runLoadTest executes a load test with the given parameters
 func runLoadTest(t *testing.T, totalRequests, concurrency int, requestFunc func(int) (*http.Response, error)) BenchmarkResult {
 	var (
@@ -224,6 +427,8 @@ func runLoadTest(t *testing.T, totalRequests, concurrency int, requestFunc func(
 		minResponseTime int64 = 999999999999 // Initialize to large value
 		maxResponseTime int64
 		totalTime       int64
+		durationsMu     sync.Mutex
+		durations       = make([]time.Duration, 0, totalRequests)
 	)
 
 	startTime := time.Now()
@@ -278,6 +483,9 @@ func runLoadTest(t *testing.T, totalRequests, concurrency int, requestFunc func(
 					t.Logf("Request %d returned status %d, body: %s", i, resp.StatusCode, string(bodyBytes))
 				} else {
 					atomic.AddInt64(&successCount, 1)
+					durationsMu.Lock()
+					durations = append(durations, time.Duration(reqDuration))
+					durationsMu.Unlock()
 				}
 
 				resp.Body.Close()
@@ -295,6 +503,8 @@ func runLoadTest(t *testing.T, totalRequests, concurrency int, requestFunc func(
 
 	requestsPerSecond := float64(successCount) / totalDuration.Seconds()
 
+	p50, p95, p99 := latencyPercentiles(durations)
+
 	return BenchmarkResult{
 		TotalRequests:     int64(totalRequests),
 		SuccessfulReqs:    successCount,
@@ -303,7 +513,11 @@ func runLoadTest(t *testing.T, totalRequests, concurrency int, requestFunc func(
 		AvgResponseTime:   avgResponseTime,
 		MinResponseTime:   time.Duration(minResponseTime),
 		MaxResponseTime:   time.Duration(maxResponseTime),
+		P50ResponseTime:   p50,
+		P95ResponseTime:   p95,
+		P99ResponseTime:   p99,
 		RequestsPerSecond: requestsPerSecond,
+		durations:         durations,
 	}
 }
 
@@ -320,6 +534,9 @@ func printBenchmarkResults(t *testing.T, name string, result BenchmarkResult) {
 		"Average Response Time: %v\n"+
 		"Min Response Time:     %v\n"+
 		"Max Response Time:     %v\n"+
+		"P50 Response Time:     %v\n"+
+		"P95 Response Time:     %v\n"+
+		"P99 Response Time:     %v\n"+
 		"Requests/Second:       %.2f\n"+
 		"========================================\n",
 		name,
@@ -332,9 +549,14 @@ func printBenchmarkResults(t *testing.T, name string, result BenchmarkResult) {
 		result.AvgResponseTime,
 		result.MinResponseTime,
 		result.MaxResponseTime,
+		result.P50ResponseTime,
+		result.P95ResponseTime,
+		result.P99ResponseTime,
 		result.RequestsPerSecond,
 	)
 
+	recordBenchmarkResult(name, result)
+
 	// Set pass/fail criteria
 	// For load tests with multiple services, 85% success rate is acceptable
 	successRate := float64(result.SuccessfulReqs) / float64(result.TotalRequests)
@@ -343,6 +565,105 @@ func printBenchmarkResults(t *testing.T, name string, result BenchmarkResult) {
 	}
 }
 
+// recordBenchmarkResult stores a result for JSON export and baseline comparison
+func recordBenchmarkResult(name string, result BenchmarkResult) {
+	benchmarkResultsMu.Lock()
+	defer benchmarkResultsMu.Unlock()
+	benchmarkResults[name] = result
+}
+
+// exportBenchmarkResults writes all recorded results to BENCHMARK_OUTPUT as JSON,
+// tagged with the current commit, so results can be tracked across commits
+func exportBenchmarkResults(t *testing.T) {
+	outputPath := getEnv("BENCHMARK_OUTPUT")
+	if outputPath == "" {
+		return
+	}
+
+	benchmarkResultsMu.Lock()
+	report := BenchmarkReport{
+		Commit:    currentCommit(),
+		Timestamp: time.Now().UTC(),
+		Results:   benchmarkResults,
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	benchmarkResultsMu.Unlock()
+	if err != nil {
+		t.Fatalf("Failed to marshal benchmark report: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		t.Fatalf("Failed to create benchmark output directory: %v", err)
+	}
+	if err := os.WriteFile(outputPath, data, 0o644); err != nil {
+		t.Fatalf("Failed to write benchmark report: %v", err)
+	}
+
+	t.Logf("✓ Benchmark results written to %s", outputPath)
+}
+
+// compareWithBaseline fails the test when p95 latency or throughput regresses
+// beyond BENCHMARK_REGRESSION_TOLERANCE (default 20%) against the report at BENCHMARK_BASELINE
+func compareWithBaseline(t *testing.T) {
+	baselinePath := getEnv("BENCHMARK_BASELINE")
+	if baselinePath == "" {
+		return
+	}
+
+	data, err := os.ReadFile(baselinePath)
+	if err != nil {
+		t.Fatalf("Failed to read benchmark baseline: %v", err)
+	}
+
+	var baseline BenchmarkReport
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		t.Fatalf("Failed to parse benchmark baseline: %v", err)
+	}
+
+	tolerance, err := strconv.ParseFloat(getEnvDefault("BENCHMARK_REGRESSION_TOLERANCE", "0.2"), 64)
+	if err != nil {
+		t.Fatalf("Invalid BENCHMARK_REGRESSION_TOLERANCE: %v", err)
+	}
+
+	t.Logf("Comparing against baseline from commit %s (tolerance %.0f%%)", baseline.Commit, tolerance*100)
+
+	benchmarkResultsMu.Lock()
+	defer benchmarkResultsMu.Unlock()
+
+	for name, current := range benchmarkResults {
+		base, ok := baseline.Results[name]
+		if !ok {
+			t.Logf("No baseline for %q - skipping comparison", name)
+			continue
+		}
+
+		if base.P95ResponseTime > 0 {
+			limit := time.Duration(float64(base.P95ResponseTime) * (1 + tolerance))
+			if current.P95ResponseTime > limit {
+				t.Errorf("%s: p95 latency regressed from %v to %v (limit %v)",
+					name, base.P95ResponseTime, current.P95ResponseTime, limit)
+			}
+		}
+
+		if base.RequestsPerSecond > 0 {
+			limit := base.RequestsPerSecond * (1 - tolerance)
+			if current.RequestsPerSecond < limit {
+				t.Errorf("%s: throughput regressed from %.2f to %.2f req/s (limit %.2f)",
+					name, base.RequestsPerSecond, current.RequestsPerSecond, limit)
+			}
+		}
+	}
+}
+
+// currentCommit returns the short git commit hash, or "unknown" outside a git checkout
+func currentCommit() string {
+	output, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(output))
+}
+
 // shouldRunBenchmark checks if benchmarking should run
 func shouldRunBenchmark() bool {
 	// Check for BENCHMARK environment variable
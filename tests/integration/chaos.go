@@ -0,0 +1,190 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"time"
+)
+
+const (
+	toxiproxyContainer = "docutab-test-toxiproxy"
+	toxiproxyImage     = "ghcr.io/shopify/toxiproxy:2.9.0"
+	toxiproxyAPIPort   = 18474
+)
+
+// Toxiproxy is a thin client for a toxiproxy container used to inject
+// network faults (latency, partitions) between services under test
+type Toxiproxy struct {
+	apiURL string
+	client *http.Client
+}
+
+// StartToxiproxy starts a toxiproxy container for chaos tests.
+// The container uses host networking so proxies can reach services on localhost.
+func (ts *TestServices) StartToxiproxy() *Toxiproxy {
+	ts.t.Logf("Starting toxiproxy container (API port %d)...", toxiproxyAPIPort)
+
+	// Check if toxiproxy container already exists and remove it
+	_ = exec.Command("docker", "rm", "-f", toxiproxyContainer).Run()
+
+	cmd := exec.Command("docker", "run", "-d",
+		"--name", toxiproxyContainer,
+		"--network", "host",
+		toxiproxyImage,
+		"-host", "0.0.0.0",
+		"-port", fmt.Sprintf("%d", toxiproxyAPIPort))
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		ts.t.Fatalf("Failed to start toxiproxy container: %v\nOutput: %s", err, string(output))
+	}
+
+	ts.toxiproxy = &Toxiproxy{
+		apiURL: fmt.Sprintf("http://localhost:%d", toxiproxyAPIPort),
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	if err := ts.waitForHealth(ts.toxiproxy.apiURL+"/version", 30*time.Second); err != nil {
+		ts.t.Fatalf("Toxiproxy failed to become ready: %v", err)
+	}
+
+	ts.t.Log("Toxiproxy container started successfully")
+	return ts.toxiproxy
+}
+
+// stopToxiproxy stops and removes the toxiproxy container
+func (ts *TestServices) stopToxiproxy() {
+	if ts.toxiproxy == nil {
+		return
+	}
+
+	ts.t.Log("Stopping toxiproxy container...")
+	cmd := exec.Command("docker", "rm", "-f", toxiproxyContainer)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		ts.t.Logf("Error stopping toxiproxy container: %v\nOutput: %s", err, string(output))
+	}
+	ts.toxiproxy = nil
+}
+
+// CreateProxy creates a proxy listening on listenPort and forwarding to upstream (host:port).
+// Returns the base URL services should use to reach the upstream through the proxy.
+func (tp *Toxiproxy) CreateProxy(name string, listenPort int, upstream string) (string, error) {
+	body := map[string]interface{}{
+		"name":     name,
+		"listen":   fmt.Sprintf("127.0.0.1:%d", listenPort),
+		"upstream": upstream,
+		"enabled":  true,
+	}
+
+	if err := tp.post("/proxies", body, http.StatusCreated); err != nil {
+		return "", fmt.Errorf("failed to create proxy %s: %w", name, err)
+	}
+
+	return fmt.Sprintf("http://127.0.0.1:%d", listenPort), nil
+}
+
+// AddLatency adds a latency toxic to all responses flowing through the named proxy
+func (tp *Toxiproxy) AddLatency(proxy string, latency, jitter time.Duration) error {
+	body := map[string]interface{}{
+		"name":     proxy + "_latency",
+		"type":     "latency",
+		"stream":   "downstream",
+		"toxicity": 1.0,
+		"attributes": map[string]interface{}{
+			"latency": latency.Milliseconds(),
+			"jitter":  jitter.Milliseconds(),
+		},
+	}
+
+	if err := tp.post("/proxies/"+proxy+"/toxics", body, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to add latency to %s: %w", proxy, err)
+	}
+	return nil
+}
+
+// SetEnabled enables or disables the named proxy. A disabled proxy refuses
+// connections, simulating a network partition to the upstream.
+func (tp *Toxiproxy) SetEnabled(proxy string, enabled bool) error {
+	body := map[string]interface{}{
+		"enabled": enabled,
+	}
+
+	if err := tp.post("/proxies/"+proxy, body, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to set %s enabled=%v: %w", proxy, enabled, err)
+	}
+	return nil
+}
+
+// Reset removes all toxics and re-enables all proxies
+func (tp *Toxiproxy) Reset() error {
+	if err := tp.post("/reset", nil, http.StatusNoContent); err != nil {
+		return fmt.Errorf("failed to reset toxiproxy: %w", err)
+	}
+	return nil
+}
+
+// post sends a JSON body to the toxiproxy API and checks the response status
+func (tp *Toxiproxy) post(path string, body interface{}, expectedStatus int) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	resp, err := tp.client.Post(tp.apiURL+path, "application/json", reader)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// RestartService kills a running service and starts it again with its original configuration
+func (ts *TestServices) RestartService(name string) error {
+	config, exists := ts.configs[name]
+	if !exists {
+		return fmt.Errorf("service %s was never started", name)
+	}
+
+	ts.StopService(name)
+	return ts.StartService(config)
+}
+
+// DropPostgresConnections terminates all client connections to the given database,
+// simulating a database failover or network blip
+func (ts *TestServices) DropPostgresConnections(dbName string) error {
+	ts.t.Logf("Dropping PostgreSQL connections to %s...", dbName)
+
+	query := fmt.Sprintf(
+		"SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = '%s' AND pid <> pg_backend_pid();",
+		dbName,
+	)
+	cmd := exec.Command("docker", "exec", ts.postgresContainer, "psql", "-U", "docutab_test", "-c", query)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to drop connections: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// DropRedisConnections kills all normal (non-replica, non-pubsub) Redis client connections
+func (ts *TestServices) DropRedisConnections() error {
+	ts.t.Log("Dropping Redis client connections...")
+
+	cmd := exec.Command("docker", "exec", ts.redisContainer, "redis-cli", "CLIENT", "KILL", "TYPE", "normal")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to drop connections: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
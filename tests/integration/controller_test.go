@@ -152,6 +152,10 @@ func TestControllerIntegration(t *testing.T) {
 	t.Run("ImageUploadAndOCR", func(t *testing.T) {
 		testImageUploadAndOCR(t, ollamaAvailable)
 	})
+
+	t.Run("ResponseSnapshots", func(t *testing.T) {
+		testResponseSnapshots(t)
+	})
 }
 
 // testDirectTextAnalysis tests POST /analyze endpoint
@@ -1649,6 +1653,96 @@ func testImageUploadAndOCR(t *testing.T, ollamaAvailable bool) {
 	t.Logf("✓ Image upload and OCR processing completed successfully (document ID: %v)", result["id"])
 }
 
+// testResponseSnapshots diffs key endpoint responses against golden files in
+// testdata/snapshots to catch unintended response-shape changes
+func testResponseSnapshots(t *testing.T) {
+	// Golden files have to be recorded against a running stack before comparisons can pass
+	if !*updateSnapshots && getEnvDefault("SNAPSHOTS", "false") != "true" {
+		t.Skip("Skipping response snapshots - set SNAPSHOTS=true to compare, or pass -update to record")
+	}
+
+	client := &http.Client{Timeout: 120 * time.Second}
+
+	// Analyze
+	analyzeBody, _ := json.Marshal(map[string]interface{}{
+		"text": fmt.Sprintf("Snapshot test %d: Climate change is a pressing global issue affecting science and technology.", time.Now().UnixNano()),
+	})
+	analyzeResp := snapshotRequest(t, client, http.MethodPost, controllerURL+"/api/analyze", analyzeBody)
+	AssertJSONSnapshot(t, "analyze", analyzeResp)
+
+	// Scrape
+	scrapeBody, _ := json.Marshal(map[string]interface{}{"url": "https://example.com"})
+	scrapeResp := snapshotRequest(t, client, http.MethodPost, controllerURL+"/api/scrape", scrapeBody)
+	AssertJSONSnapshot(t, "scrape", scrapeResp)
+
+	// Search
+	searchBody, _ := json.Marshal(map[string]interface{}{"tags": []string{"example.com"}, "fuzzy": true})
+	searchResp := snapshotRequest(t, client, http.MethodPost, controllerURL+"/api/search", searchBody)
+	AssertJSONSnapshot(t, "search", searchResp)
+
+	// Request listing
+	listResp := snapshotRequest(t, client, http.MethodGet, controllerURL+"/api/requests?limit=100&offset=0", nil)
+	AssertJSONSnapshot(t, "requests_list", listResp)
+
+	// Sitemaps
+	sitemapResp := snapshotRequest(t, client, http.MethodGet, controllerURL+"/sitemap.xml", nil)
+	AssertTextSnapshot(t, "sitemap.xml", []byte(sitemapSkeleton(string(sitemapResp))))
+
+	imageSitemapResp := snapshotRequest(t, client, http.MethodGet, controllerURL+"/images-sitemap.xml", nil)
+	AssertTextSnapshot(t, "images-sitemap.xml", []byte(sitemapSkeleton(string(imageSitemapResp))))
+
+	// Content page for the scraped example.com document (only served when scored above threshold)
+	var listResult map[string]interface{}
+	if err := json.Unmarshal(listResp, &listResult); err != nil {
+		t.Fatalf("Failed to decode list response: %v", err)
+	}
+	requests, _ := listResult["requests"].([]interface{})
+	for _, req := range requests {
+		reqMap, ok := req.(map[string]interface{})
+		if !ok || reqMap["source_url"] != "https://example.com" {
+			continue
+		}
+		if slug, ok := reqMap["slug"].(string); ok && slug != "" {
+			contentResp := snapshotRequest(t, client, http.MethodGet, fmt.Sprintf("%s/content/%s", controllerURL, slug), nil)
+			AssertTextSnapshot(t, "content_page.html", []byte(htmlSkeleton(string(contentResp))))
+			break
+		}
+	}
+
+	t.Log("✓ Response snapshots compared")
+}
+
+// snapshotRequest performs a request for a snapshot test and returns the body,
+// failing the test on non-2xx responses
+func snapshotRequest(t *testing.T, client *http.Client, method, url string, body []byte) []byte {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		t.Fatalf("%s %s returned status %d: %s", method, url, resp.StatusCode, string(respBody))
+	}
+
+	return respBody
+}
+
 // Helper function to assert a field exists in a map
 func assertFieldExists(t *testing.T, m map[string]interface{}, field string) {
 	t.Helper()
@@ -26,6 +26,7 @@ type ServiceConfig struct {
 type TestServices struct {
 	t                 *testing.T
 	processes         map[string]*exec.Cmd
+	configs           map[string]ServiceConfig
 	ollamaUp          bool
 	tempDBDir         string
 	mockOllama        *MockOllamaServer
@@ -34,6 +35,7 @@ type TestServices struct {
 	redisPort         int
 	postgresContainer string
 	postgresPort      int
+	toxiproxy         *Toxiproxy
 }
 
 // NewTestServices creates a new test services manager
@@ -46,6 +48,7 @@ func NewTestServices(t *testing.T) *TestServices {
 	ts := &TestServices{
 		t:              t,
 		processes:      make(map[string]*exec.Cmd),
+		configs:        make(map[string]ServiceConfig),
 		tempDBDir:      tempDir,
 		mockOllamaPort: 11435, // Use port 11435 to avoid conflict with real Ollama
 		redisPort:      16379, // Use port 16379 for test Redis
@@ -80,6 +83,7 @@ func (ts *TestServices) StartService(config ServiceConfig) error {
 	}
 
 	ts.processes[config.Name] = cmd
+	ts.configs[config.Name] = config
 
 	// Wait for service to be healthy
 	if err := ts.waitForHealth(config.HealthCheck, 30*time.Second); err != nil {
@@ -114,6 +118,9 @@ func (ts *TestServices) StopAll() {
 		ts.StopService(name)
 	}
 
+	// Stop toxiproxy if chaos tests started it
+	ts.stopToxiproxy()
+
 	// Stop mock Ollama server
 	ts.stopMockOllama()
 
@@ -0,0 +1,259 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// updateSnapshots rewrites golden files instead of comparing against them.
+// It is bound to the -update test flag in snapshot_test.go.
+var updateSnapshots = new(bool)
+
+// snapshotDir is where golden files are stored, relative to the test package
+const snapshotDir = "testdata/snapshots"
+
+// Scrubbers replace volatile values in text snapshots (HTML, XML) with stable placeholders
+var snapshotScrubbers = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(<(?:image:)?loc>)[^<]*(</(?:image:)?loc>)`), "$1<url>$2"},
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<uuid>"},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?`), "<timestamp>"},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}`), "<date>"},
+}
+
+// modelGeneratedFields are only present when Ollama answered, so they are left out of
+// JSON shapes and one golden file holds with Ollama online or offline
+var modelGeneratedFields = map[string]bool{
+	"synopsis":       true,
+	"ai_detection":   true,
+	"quality_score":  true,
+	"link_score":     true,
+	"image_summary":  true,
+	"extracted_text": true,
+}
+
+// AssertJSONSnapshot compares the shape of a JSON response against its golden file.
+// Values are replaced by their JSON type so that only structural changes
+// (added, removed or retyped fields) cause a diff, not AI-generated content.
+func AssertJSONSnapshot(t *testing.T, name string, body []byte) {
+	t.Helper()
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		t.Fatalf("Snapshot %s: response is not valid JSON: %v", name, err)
+	}
+
+	shape, err := marshalShape(normalizeJSONShape(v), "  ")
+	if err != nil {
+		t.Fatalf("Snapshot %s: failed to marshal shape: %v", name, err)
+	}
+
+	assertSnapshot(t, name+".json", shape)
+}
+
+// AssertTextSnapshot compares a text response (HTML page, sitemap) against its
+// golden file after scrubbing UUIDs, timestamps and dates
+func AssertTextSnapshot(t *testing.T, name string, body []byte) {
+	t.Helper()
+
+	assertSnapshot(t, name, scrubSnapshot(string(body)))
+}
+
+// assertSnapshot compares got against the named golden file, or records it when -update is set.
+// A missing golden file fails the test so a fresh checkout can't pass without comparing anything.
+func assertSnapshot(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join(snapshotDir, name)
+
+	if *updateSnapshots {
+		if err := os.MkdirAll(snapshotDir, 0o755); err != nil {
+			t.Fatalf("Failed to create snapshot directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("Failed to write snapshot %s: %v", path, err)
+		}
+		t.Logf("✓ Recorded snapshot %s", path)
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("Snapshot %s does not exist; record it with -update and commit it", path)
+	}
+	if err != nil {
+		t.Fatalf("Failed to read snapshot %s: %v", path, err)
+	}
+
+	if string(want) != got {
+		t.Errorf("Response does not match snapshot %s (re-run with -update if the change is intended):\n%s",
+			path, diffLines(string(want), got))
+	}
+}
+
+// normalizeJSONShape replaces every leaf value with its JSON type name.
+// Arrays collapse to a single element describing the merged shape of all items.
+func normalizeJSONShape(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if modelGeneratedFields[k] {
+				continue
+			}
+			out[k] = normalizeJSONShape(item)
+		}
+		return out
+	case []interface{}:
+		if len(val) == 0 {
+			return []interface{}{}
+		}
+		var merged interface{}
+		for _, item := range val {
+			merged = mergeJSONShapes(merged, normalizeJSONShape(item))
+		}
+		return []interface{}{merged}
+	case string:
+		return "<string>"
+	case float64:
+		return "<number>"
+	case bool:
+		return "<bool>"
+	default:
+		return nil
+	}
+}
+
+// marshalShape encodes a normalized shape without HTML escaping so
+// placeholders like "<string>" stay readable in golden files
+func marshalShape(shape interface{}, indent string) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(shape); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// mergeJSONShapes combines two normalized shapes so that objects in an array
+// report the union of their fields
+func mergeJSONShapes(a, b interface{}) interface{} {
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if !aok || !bok {
+		if a == nil {
+			return b
+		}
+		return a
+	}
+
+	out := make(map[string]interface{}, len(am))
+	for k, v := range am {
+		out[k] = v
+	}
+	for k, v := range bm {
+		out[k] = mergeJSONShapes(out[k], v)
+	}
+	return out
+}
+
+var (
+	htmlIgnored = regexp.MustCompile(`(?is)<!--.*?-->|<!doctype[^>]*>`)
+	htmlScript  = regexp.MustCompile(`(?is)(<(script|style)\b[^>]*>).*?(</(script|style)>)`)
+	htmlTag     = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9:-]*)((?:"[^"]*"|'[^']*'|[^'">])*)>`)
+	htmlAttr    = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*(?:=\s*(?:"[^"]*"|'[^']*'|[^\s>]+))?`)
+)
+
+// htmlSkeleton reduces a page to one tag per line with attribute names but no values
+// or text, and collapses repeated runs of lines. Titles, descriptions, JSON-LD and tag
+// lists on content pages come from the model, so only the page structure is compared.
+func htmlSkeleton(s string) string {
+	s = htmlIgnored.ReplaceAllString(s, "")
+	s = htmlScript.ReplaceAllString(s, "$1$3")
+
+	var lines []string
+	for _, m := range htmlTag.FindAllStringSubmatch(s, -1) {
+		closing, name, rest := m[1], strings.ToLower(m[2]), strings.TrimSuffix(strings.TrimSpace(m[3]), "/")
+
+		line := "<" + closing + name
+		if closing == "" {
+			for _, attr := range htmlAttr.FindAllStringSubmatch(rest, -1) {
+				line += " " + strings.ToLower(attr[1])
+			}
+		}
+		lines = append(lines, line+">")
+		lines = collapseRepeats(lines, 8)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// collapseRepeats drops the tail of lines if it repeats the run of up to maxRun lines before it
+func collapseRepeats(lines []string, maxRun int) []string {
+	for n := 1; n <= maxRun && 2*n <= len(lines); n++ {
+		tail, prev := lines[len(lines)-n:], lines[len(lines)-2*n:len(lines)-n]
+		if strings.Join(tail, "\n") == strings.Join(prev, "\n") {
+			return lines[:len(lines)-n]
+		}
+	}
+	return lines
+}
+
+// sitemapSkeleton reduces a sitemap to its header, first <url> entry and
+// closing tag, so snapshots don't depend on how many documents exist
+func sitemapSkeleton(s string) string {
+	start := strings.Index(s, "<url>")
+	end := strings.Index(s, "</url>")
+	if start == -1 || end == -1 {
+		return s
+	}
+
+	tail := ""
+	if closing := strings.LastIndex(s, "</urlset>"); closing != -1 {
+		tail = s[closing:]
+	}
+	return s[:end+len("</url>")] + "\n" + tail
+}
+
+// scrubSnapshot applies all snapshot scrubbers to s
+func scrubSnapshot(s string) string {
+	for _, scrubber := range snapshotScrubbers {
+		s = scrubber.pattern.ReplaceAllString(s, scrubber.replacement)
+	}
+	return s
+}
+
+// diffLines returns a minimal line-by-line diff of want and got for test output
+func diffLines(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	var b strings.Builder
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w == g {
+			continue
+		}
+		if i < len(wantLines) {
+			b.WriteString("- " + w + "\n")
+		}
+		if i < len(gotLines) {
+			b.WriteString("+ " + g + "\n")
+		}
+	}
+	return b.String()
+}
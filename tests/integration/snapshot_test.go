@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+)
+
+// Usage: go test -run TestControllerIntegration -update
+func init() {
+	updateSnapshots = flag.Bool("update", false, "update golden snapshot files in testdata/snapshots")
+}
+
+// TestNormalizeJSONShape verifies snapshot normalization keeps structure but drops values
+func TestNormalizeJSONShape(t *testing.T) {
+	input := `{
+		"id": "3f2a",
+		"count": 2,
+		"seo_enabled": true,
+		"deleted_at": null,
+		"tags": ["a", "b"],
+		"images": [{"url": "x"}, {"url": "y", "alt": "z"}],
+		"empty": [],
+		"synopsis": "model output",
+		"analyzer_metadata": {"quality_score": {"score": 0.9}, "sentiment": "positive"}
+	}`
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(input), &v); err != nil {
+		t.Fatalf("Failed to unmarshal input: %v", err)
+	}
+
+	got, err := marshalShape(normalizeJSONShape(v), "")
+	if err != nil {
+		t.Fatalf("Failed to marshal shape: %v", err)
+	}
+
+	want := `{"analyzer_metadata":{"sentiment":"<string>"},` +
+		`"count":"<number>","deleted_at":null,"empty":[],"id":"<string>",` +
+		`"images":[{"alt":"<string>","url":"<string>"}],` +
+		`"seo_enabled":"<bool>","tags":["<string>"]}`
+
+	if got != want+"\n" {
+		t.Errorf("Unexpected shape:\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+// TestScrubSnapshot verifies volatile values are replaced in text snapshots
+func TestScrubSnapshot(t *testing.T) {
+	input := `<url><loc>http://localhost:18080/content/example-1</loc><lastmod>2025-10-01</lastmod></url>` +
+		`<meta property="article:published_time" content="2025-10-01T12:30:00Z">` +
+		`<a href="/api/requests/0b9f3c5e-8f7d-4a1b-9c2d-3e4f5a6b7c8d">`
+
+	want := `<url><loc><url></loc><lastmod><date></lastmod></url>` +
+		`<meta property="article:published_time" content="<timestamp>">` +
+		`<a href="/api/requests/<uuid>">`
+
+	if got := scrubSnapshot(input); got != want {
+		t.Errorf("Unexpected scrub result:\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+// TestHTMLSkeleton verifies content pages reduce to structure without model text
+func TestHTMLSkeleton(t *testing.T) {
+	page := func(title string, tags ...string) string {
+		var b strings.Builder
+		b.WriteString(`<!DOCTYPE html><html><head><title>` + title + `</title>`)
+		b.WriteString(`<meta name="description" content="` + title + ` > summary">`)
+		b.WriteString(`<script type="application/ld+json">{"headline": "<b>` + title + `</b>"}</script></head>`)
+		b.WriteString(`<body><!-- generated --><h1 class=title>` + title + `</h1><ul>`)
+		for _, tag := range tags {
+			b.WriteString(`<li><a href="/tags/` + tag + `">` + tag + `</a></li>`)
+		}
+		b.WriteString(`</ul><img src="x.png" alt="` + title + `"/></body></html>`)
+		return b.String()
+	}
+
+	online := htmlSkeleton(page("An AI written title", "go", "testing", "snapshots"))
+	offline := htmlSkeleton(page("Example Domain", "example"))
+	if online != offline {
+		t.Errorf("Expected the same skeleton regardless of generated text:\n%s\nvs\n%s", online, offline)
+	}
+
+	want := strings.Join([]string{
+		"<html>", "<head>", "<title>", "</title>", "<meta name content>",
+		"<script type>", "</script>", "</head>",
+		"<body>", "<h1 class>", "</h1>", "<ul>", "<li>", "<a href>", "</a>", "</li>", "</ul>",
+		"<img src alt>", "</body>", "</html>",
+	}, "\n") + "\n"
+	if online != want {
+		t.Errorf("Unexpected skeleton:\n%s\nwant:\n%s", online, want)
+	}
+}
@@ -0,0 +1,262 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// soakGauges are the Prometheus series sampled from each service during a soak test
+var soakGauges = []string{
+	"go_goroutines",
+	"go_memstats_heap_inuse_bytes",
+	"db_connections_open",
+}
+
+// soakServices maps service names to their metrics endpoints
+var soakServices = map[string]string{
+	"controller":   benchControllerURL + "/metrics",
+	"scraper":      benchScraperURL + "/metrics",
+	"textanalyzer": benchTextAnalyzerURL + "/metrics",
+}
+
+// TestSoakResourceLeaks runs a low, steady load for hours while sampling
+// goroutines, heap, DB connections and Redis keys, failing on monotonic growth
+func TestSoakResourceLeaks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping soak test in short mode")
+	}
+
+	if !shouldRunBenchmark() {
+		t.Skip("Skipping soak test - set BENCHMARK=true to run")
+	}
+
+	durationStr := getEnv("SOAK_DURATION")
+	if durationStr == "" {
+		t.Skip("Skipping soak test - set SOAK_DURATION (e.g. 4h) to run")
+	}
+
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil {
+		t.Fatalf("Invalid SOAK_DURATION: %v", err)
+	}
+
+	interval, err := time.ParseDuration(getEnvDefault("SOAK_SAMPLE_INTERVAL", "1m"))
+	if err != nil {
+		t.Fatalf("Invalid SOAK_SAMPLE_INTERVAL: %v", err)
+	}
+
+	services := NewTestServices(t)
+	defer services.StopAll()
+
+	setupBenchmarkServices(t, services)
+
+	// Let services settle before taking the first sample
+	time.Sleep(10 * time.Second)
+
+	t.Logf("Starting soak test for %v (sampling every %v)", duration, interval)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	var sent, failed int64
+
+	// Steady background load: 2 workers, one analyze request per second each
+	for w := 0; w < 2; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			runSoakLoad(worker, stop, &sent, &failed)
+		}(w)
+	}
+
+	series := make(map[string][]float64)
+	deadline := time.Now().Add(duration)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for time.Now().Before(deadline) {
+		sampleSoakMetrics(t, services, series)
+		<-ticker.C
+	}
+	sampleSoakMetrics(t, services, series)
+
+	close(stop)
+	wg.Wait()
+
+	t.Logf("Soak load complete: %d requests sent, %d failed", atomic.LoadInt64(&sent), atomic.LoadInt64(&failed))
+
+	for name, samples := range series {
+		first, last := samples[0], samples[len(samples)-1]
+		t.Logf("%-50s first=%-12.0f last=%-12.0f samples=%d", name, first, last, len(samples))
+
+		if detectMonotonicGrowth(samples) {
+			t.Errorf("Possible leak: %s grew monotonically from %.0f to %.0f over %v", name, first, last, duration)
+		}
+	}
+}
+
+// runSoakLoad sends analyze requests at a fixed rate until stop is closed
+func runSoakLoad(worker int, stop <-chan struct{}, sent, failed *int64) {
+	client := &http.Client{Timeout: 60 * time.Second}
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		body, _ := json.Marshal(map[string]interface{}{
+			"text": fmt.Sprintf("Soak worker %d request %d: steady background load for leak detection", worker, i),
+		})
+
+		atomic.AddInt64(sent, 1)
+		resp, err := client.Post(benchControllerURL+"/api/analyze", "application/json", bytes.NewReader(body))
+		if err != nil {
+			atomic.AddInt64(failed, 1)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+			atomic.AddInt64(failed, 1)
+		}
+		resp.Body.Close()
+	}
+}
+
+// sampleSoakMetrics appends one sample per tracked series
+func sampleSoakMetrics(t *testing.T, services *TestServices, series map[string][]float64) {
+	t.Helper()
+
+	for service, url := range soakServices {
+		values, err := scrapeGauges(url, soakGauges)
+		if err != nil {
+			t.Logf("Warning: failed to scrape %s metrics: %v", service, err)
+			continue
+		}
+		for name, value := range values {
+			key := service + "/" + name
+			series[key] = append(series[key], value)
+		}
+	}
+
+	if keys, err := redisKeyCount(services); err != nil {
+		t.Logf("Warning: failed to count Redis keys: %v", err)
+	} else {
+		series["redis/keys"] = append(series["redis/keys"], keys)
+	}
+}
+
+// scrapeGauges fetches a Prometheus text endpoint and sums the values of the
+// named series across all label sets
+func scrapeGauges(url string, names []string) (map[string]float64, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	values := make(map[string]float64)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name := line
+		if i := strings.IndexAny(line, "{ "); i != -1 {
+			name = line[:i]
+		}
+		if !wanted[name] {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		value, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+		if err != nil {
+			continue
+		}
+		values[name] += value
+	}
+
+	return values, scanner.Err()
+}
+
+// redisKeyCount returns the number of keys in the test Redis instance
+func redisKeyCount(services *TestServices) (float64, error) {
+	output, err := exec.Command("docker", "exec", services.redisContainer, "redis-cli", "DBSIZE").Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+}
+
+// detectMonotonicGrowth reports whether samples (after a 10% warm-up) almost never
+// decrease and grew by more than 20% overall - the signature of a slow leak,
+// as opposed to a plateau or a sawtooth caused by GC and pool recycling
+func detectMonotonicGrowth(samples []float64) bool {
+	warmup := len(samples) / 10
+	samples = samples[warmup:]
+	if len(samples) < 5 {
+		return false
+	}
+
+	increases := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i] > samples[i-1] {
+			increases++
+		} else if samples[i] < samples[i-1] {
+			increases--
+		}
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	steadilyRising := float64(increases) >= 0.8*float64(len(samples)-1)
+	grewSignificantly := last > first*1.2 && last-first >= 1
+
+	return steadilyRising && grewSignificantly
+}
+
+// TestDetectMonotonicGrowth verifies leak detection on synthetic series
+func TestDetectMonotonicGrowth(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float64
+		want    bool
+	}{
+		{"steady leak", []float64{10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, true},
+		{"plateau", []float64{10, 12, 14, 15, 15, 15, 15, 15, 15, 15, 15}, false},
+		{"sawtooth", []float64{10, 14, 9, 15, 10, 14, 9, 15, 10, 14, 10}, false},
+		{"small growth", []float64{100, 101, 102, 103, 104, 105, 106, 107, 108, 109, 110}, false},
+		{"too few samples", []float64{1, 2, 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectMonotonicGrowth(tt.samples); got != tt.want {
+				t.Errorf("detectMonotonicGrowth(%v) = %v, want %v", tt.samples, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package integration
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TraceSpan is a single span retrieved from Tempo
+type TraceSpan struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Kind         string
+	Service      string
+	Attributes   map[string]string
+}
+
+// Trace is a complete trace retrieved from Tempo
+type Trace struct {
+	TraceID string
+	Spans   []TraceSpan
+}
+
+// SpanMatcher selects spans by service, name, kind and attribute values.
+// Empty fields match anything; NameContains and attribute values match substrings.
+type SpanMatcher struct {
+	Service      string
+	NameContains string
+	Kind         string // e.g. SPAN_KIND_SERVER, SPAN_KIND_CLIENT
+	Attributes   map[string]string
+	Match        func(TraceSpan) bool // optional custom predicate
+}
+
+// TempoClient queries the Tempo HTTP API for traces
+type TempoClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewTempoClient creates a Tempo client. baseURL defaults to TEMPO_URL or http://localhost:3200.
+func NewTempoClient(baseURL string) *TempoClient {
+	if baseURL == "" {
+		baseURL = os.Getenv("TEMPO_URL")
+	}
+	if baseURL == "" {
+		baseURL = "http://localhost:3200"
+	}
+	return &TempoClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// NewTraceparent generates a random W3C traceparent header value and returns it
+// along with its trace ID and span ID. Sending it with a request makes the
+// services continue our trace, so the trace ID is known up front.
+func NewTraceparent() (header, traceID, spanID string) {
+	traceBytes := make([]byte, 16)
+	spanBytes := make([]byte, 8)
+	_, _ = rand.Read(traceBytes)
+	_, _ = rand.Read(spanBytes)
+
+	traceID = hex.EncodeToString(traceBytes)
+	spanID = hex.EncodeToString(spanBytes)
+	return fmt.Sprintf("00-%s-%s-01", traceID, spanID), traceID, spanID
+}
+
+// GetTrace fetches a trace by ID. Returns (nil, nil) if Tempo has not ingested it yet.
+func (c *TempoClient) GetTrace(traceID string) (*Trace, error) {
+	resp, err := c.client.Get(c.baseURL + "/api/traces/" + traceID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tempo returned status %d", resp.StatusCode)
+	}
+
+	var payload tempoTraceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode trace: %w", err)
+	}
+
+	return payload.toTrace(traceID), nil
+}
+
+// WaitForTrace polls Tempo until the trace contains spans from all the given
+// services, or the timeout expires. Spans arrive in batches, so the first
+// successful fetch may be incomplete.
+func (c *TempoClient) WaitForTrace(t *testing.T, traceID string, timeout time.Duration, services ...string) *Trace {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	var trace *Trace
+	var lastErr error
+
+	for time.Now().Before(deadline) {
+		trace, lastErr = c.GetTrace(traceID)
+		if trace != nil && trace.hasServices(services) {
+			return trace
+		}
+		time.Sleep(1 * time.Second)
+	}
+
+	if trace != nil {
+		t.Fatalf("Trace %s incomplete after %v: found services %v, want %v", traceID, timeout, trace.Services(), services)
+	}
+	t.Fatalf("Trace %s not found in Tempo within %v (last error: %v)", traceID, timeout, lastErr)
+	return nil
+}
+
+// Services returns the distinct service names that emitted spans in the trace
+func (tr *Trace) Services() []string {
+	seen := make(map[string]bool)
+	var services []string
+	for _, span := range tr.Spans {
+		if !seen[span.Service] {
+			seen[span.Service] = true
+			services = append(services, span.Service)
+		}
+	}
+	return services
+}
+
+// FindSpans returns all spans matching m
+func (tr *Trace) FindSpans(m SpanMatcher) []TraceSpan {
+	var matches []TraceSpan
+	for _, span := range tr.Spans {
+		if m.matches(span) {
+			matches = append(matches, span)
+		}
+	}
+	return matches
+}
+
+// Span returns the span with the given ID, or nil
+func (tr *Trace) Span(spanID string) *TraceSpan {
+	for i := range tr.Spans {
+		if tr.Spans[i].SpanID == spanID {
+			return &tr.Spans[i]
+		}
+	}
+	return nil
+}
+
+// IsDescendant reports whether span is a (transitive) child of ancestorSpanID
+func (tr *Trace) IsDescendant(span TraceSpan, ancestorSpanID string) bool {
+	parentID := span.ParentSpanID
+	for depth := 0; parentID != "" && depth < len(tr.Spans)+1; depth++ {
+		if parentID == ancestorSpanID {
+			return true
+		}
+		parent := tr.Span(parentID)
+		if parent == nil {
+			return false
+		}
+		parentID = parent.ParentSpanID
+	}
+	return false
+}
+
+// AssertSpan fails the test unless at least one span matches m, returning the first match
+func AssertSpan(t *testing.T, tr *Trace, m SpanMatcher) TraceSpan {
+	t.Helper()
+
+	matches := tr.FindSpans(m)
+	if len(matches) == 0 {
+		t.Fatalf("No span matching %+v in trace %s. Spans:\n%s", m, tr.TraceID, tr.describe())
+	}
+	return matches[0]
+}
+
+// AssertSpanChain fails the test unless each matcher has a matching span that
+// descends from a matching span of the previous matcher, e.g.
+// controller server span → scraper server span → Ollama client span.
+// The first matcher may be anchored to rootSpanID (pass "" to skip).
+func AssertSpanChain(t *testing.T, tr *Trace, rootSpanID string, chain ...SpanMatcher) {
+	t.Helper()
+
+	var parents []TraceSpan
+	for i, m := range chain {
+		var next []TraceSpan
+		for _, span := range tr.FindSpans(m) {
+			if i == 0 {
+				if rootSpanID == "" || tr.IsDescendant(span, rootSpanID) {
+					next = append(next, span)
+				}
+				continue
+			}
+			for _, parent := range parents {
+				if tr.IsDescendant(span, parent.SpanID) {
+					next = append(next, span)
+					break
+				}
+			}
+		}
+
+		if len(next) == 0 {
+			t.Fatalf("Span chain broken at step %d (%+v) in trace %s. Spans:\n%s", i+1, m, tr.TraceID, tr.describe())
+		}
+		t.Logf("✓ Step %d: %s span %q", i+1, next[0].Service, next[0].Name)
+		parents = next
+	}
+}
+
+// hasServices reports whether the trace has spans from every named service
+func (tr *Trace) hasServices(services []string) bool {
+	present := make(map[string]bool)
+	for _, span := range tr.Spans {
+		present[span.Service] = true
+	}
+	for _, service := range services {
+		if !present[service] {
+			return false
+		}
+	}
+	return len(tr.Spans) > 0
+}
+
+// describe renders the trace's spans for failure messages
+func (tr *Trace) describe() string {
+	var b strings.Builder
+	for _, span := range tr.Spans {
+		fmt.Fprintf(&b, "  [%s] %s (span=%s parent=%s kind=%s)\n", span.Service, span.Name, span.SpanID, span.ParentSpanID, span.Kind)
+	}
+	return b.String()
+}
+
+// matches reports whether span satisfies the matcher
+func (m SpanMatcher) matches(span TraceSpan) bool {
+	if m.Service != "" && span.Service != m.Service {
+		return false
+	}
+	if m.NameContains != "" && !strings.Contains(span.Name, m.NameContains) {
+		return false
+	}
+	if m.Kind != "" && span.Kind != m.Kind {
+		return false
+	}
+	for key, want := range m.Attributes {
+		if !strings.Contains(span.Attributes[key], want) {
+			return false
+		}
+	}
+	if m.Match != nil && !m.Match(span) {
+		return false
+	}
+	return true
+}
+
+// tempoTraceResponse is the OTLP JSON document returned by GET /api/traces/{id}.
+// Tempo versions differ in the names of the resource and scope span lists.
+type tempoTraceResponse struct {
+	Batches       []tempoResourceSpans `json:"batches"`
+	ResourceSpans []tempoResourceSpans `json:"resourceSpans"`
+}
+
+type tempoResourceSpans struct {
+	Resource struct {
+		Attributes []tempoAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans                  []tempoScopeSpans `json:"scopeSpans"`
+	InstrumentationLibrarySpans []tempoScopeSpans `json:"instrumentationLibrarySpans"`
+}
+
+type tempoScopeSpans struct {
+	Spans []tempoSpan `json:"spans"`
+}
+
+type tempoSpan struct {
+	TraceID      string           `json:"traceId"`
+	SpanID       string           `json:"spanId"`
+	ParentSpanID string           `json:"parentSpanId"`
+	Name         string           `json:"name"`
+	Kind         string           `json:"kind"`
+	Attributes   []tempoAttribute `json:"attributes"`
+}
+
+type tempoAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string `json:"stringValue"`
+		IntValue    *string `json:"intValue"`
+		BoolValue   *bool   `json:"boolValue"`
+	} `json:"value"`
+}
+
+// stringValue renders an attribute value as a string
+func (a tempoAttribute) stringValue() string {
+	switch {
+	case a.Value.StringValue != nil:
+		return *a.Value.StringValue
+	case a.Value.IntValue != nil:
+		return *a.Value.IntValue
+	case a.Value.BoolValue != nil:
+		return fmt.Sprintf("%v", *a.Value.BoolValue)
+	}
+	return ""
+}
+
+// toTrace flattens the OTLP response into a Trace with hex-encoded IDs
+func (r tempoTraceResponse) toTrace(traceID string) *Trace {
+	trace := &Trace{TraceID: traceID}
+
+	for _, rs := range append(r.Batches, r.ResourceSpans...) {
+		service := ""
+		for _, attr := range rs.Resource.Attributes {
+			if attr.Key == "service.name" {
+				service = attr.stringValue()
+			}
+		}
+
+		for _, ss := range append(rs.ScopeSpans, rs.InstrumentationLibrarySpans...) {
+			for _, s := range ss.Spans {
+				attrs := make(map[string]string, len(s.Attributes))
+				for _, attr := range s.Attributes {
+					attrs[attr.Key] = attr.stringValue()
+				}
+
+				trace.Spans = append(trace.Spans, TraceSpan{
+					TraceID:      decodeOTLPID(s.TraceID),
+					SpanID:       decodeOTLPID(s.SpanID),
+					ParentSpanID: decodeOTLPID(s.ParentSpanID),
+					Name:         s.Name,
+					Kind:         s.Kind,
+					Service:      service,
+					Attributes:   attrs,
+				})
+			}
+		}
+	}
+
+	return trace
+}
+
+// decodeOTLPID converts a base64-encoded OTLP trace/span ID to lowercase hex.
+// IDs that are already hex are returned unchanged.
+func decodeOTLPID(id string) string {
+	if id == "" {
+		return ""
+	}
+	if _, err := hex.DecodeString(id); err == nil && (len(id) == 16 || len(id) == 32) {
+		return strings.ToLower(id)
+	}
+	if raw, err := base64.StdEncoding.DecodeString(id); err == nil && (len(raw) == 8 || len(raw) == 16) {
+		return hex.EncodeToString(raw)
+	}
+	return strings.ToLower(id)
+}
@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestTempoTraceParsing verifies OTLP JSON from Tempo is flattened with hex IDs and parentage
+func TestTempoTraceParsing(t *testing.T) {
+	// IDs are base64-encoded as returned by Tempo's /api/traces endpoint
+	payload := `{
+		"batches": [
+			{
+				"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "controller"}}]},
+				"scopeSpans": [{"spans": [
+					{"traceId": "AAECAwQFBgcICQoLDA0ODw==", "spanId": "AQEBAQEBAQE=", "parentSpanId": "", "name": "POST /api/scrape", "kind": "SPAN_KIND_SERVER"}
+				]}]
+			},
+			{
+				"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "scraper"}}]},
+				"scopeSpans": [{"spans": [
+					{"traceId": "AAECAwQFBgcICQoLDA0ODw==", "spanId": "AgICAgICAgI=", "parentSpanId": "AQEBAQEBAQE=", "name": "POST /api/scrape", "kind": "SPAN_KIND_SERVER"},
+					{"traceId": "AAECAwQFBgcICQoLDA0ODw==", "spanId": "AwMDAwMDAwM=", "parentSpanId": "AgICAgICAgI=", "name": "HTTP POST", "kind": "SPAN_KIND_CLIENT",
+					 "attributes": [{"key": "url.full", "value": {"stringValue": "http://ollama:11434/api/generate"}}]}
+				]}]
+			}
+		]
+	}`
+
+	var resp tempoTraceResponse
+	if err := json.Unmarshal([]byte(payload), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+
+	trace := resp.toTrace("000102030405060708090a0b0c0d0e0f")
+
+	if len(trace.Spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(trace.Spans))
+	}
+
+	ollama := AssertSpan(t, trace, SpanMatcher{Service: "scraper", Kind: "SPAN_KIND_CLIENT", Match: isOllamaSpan})
+	if ollama.SpanID != "0303030303030303" {
+		t.Errorf("Expected hex span ID 0303030303030303, got %s", ollama.SpanID)
+	}
+	if ollama.TraceID != "000102030405060708090a0b0c0d0e0f" {
+		t.Errorf("Expected hex trace ID, got %s", ollama.TraceID)
+	}
+
+	if !trace.IsDescendant(ollama, "0101010101010101") {
+		t.Error("Expected Ollama span to descend from controller span")
+	}
+	if trace.IsDescendant(trace.Spans[0], ollama.SpanID) {
+		t.Error("Controller span must not descend from Ollama span")
+	}
+
+	AssertSpanChain(t, trace, "",
+		SpanMatcher{Service: "controller"},
+		SpanMatcher{Service: "scraper", Kind: "SPAN_KIND_SERVER"},
+		SpanMatcher{Service: "scraper", Kind: "SPAN_KIND_CLIENT", Match: isOllamaSpan},
+	)
+}
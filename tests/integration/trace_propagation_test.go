@@ -3,47 +3,37 @@ package integration
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
-	"os/exec"
 	"strings"
 	"testing"
 	"time"
 )
 
-// TestHTTPTracePropagation tests that trace IDs are properly propagated
-// across HTTP requests and logged in structured logs
+// TestHTTPTracePropagation tests that trace context is propagated across
+// services by querying Tempo for the spans of a request with a known trace ID
 func TestHTTPTracePropagation(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration tests in short mode")
 	}
 
-	// This test verifies the fix for the middleware order issue where
-	// trace_id was empty in logs because logging middleware executed
-	// before tracing middleware created the span
+	// Each request carries a traceparent header we generate, so the services
+	// continue our trace and we can look it up in Tempo by ID. Server spans
+	// must be children of our injected span, proving propagation works.
 
 	// Wait for services to be ready
 	waitForService(t, "http://localhost:9080/health", "controller", 30*time.Second)
 	waitForService(t, "http://localhost:9081/health", "scraper", 30*time.Second)
 	waitForService(t, "http://localhost:9082/health", "textanalyzer", 30*time.Second)
 
-	t.Run("ControllerHTTPRequestHasTraceID", func(t *testing.T) {
-		// Make a scrape request to controller
-		requestBody := map[string]string{
-			"url": "https://example.com",
-		}
-		bodyBytes, err := json.Marshal(requestBody)
-		if err != nil {
-			t.Fatalf("Failed to marshal request body: %v", err)
-		}
+	tempo := NewTempoClient("")
+	waitForService(t, tempo.baseURL+"/ready", "tempo", 30*time.Second)
 
-		resp, err := http.Post("http://localhost:9080/api/scrape",
-			"application/json",
-			bytes.NewBuffer(bodyBytes))
-		if err != nil {
-			t.Fatalf("Failed to make scrape request: %v", err)
-		}
+	t.Run("ControllerToScraperToOllama", func(t *testing.T) {
+		traceparent, traceID, rootSpanID := NewTraceparent()
+
+		resp := postWithTraceparent(t, "http://localhost:9080/api/scrape", traceparent,
+			map[string]interface{}{"url": "https://example.com"})
 		defer resp.Body.Close()
 
 		// Request should succeed (or fail gracefully)
@@ -52,168 +42,84 @@ func TestHTTPTracePropagation(t *testing.T) {
 			t.Fatalf("Unexpected status code: %d, body: %s", resp.StatusCode, string(body))
 		}
 
-		// Give logs time to flush
-		time.Sleep(500 * time.Millisecond)
-
-		// Check controller logs for trace_id
-		logs := getContainerLogs(t, "docutag-controller", 50)
-
-		// Verify at least one HTTP request log has a non-empty trace_id
-		foundTraceID := false
-		for _, line := range logs {
-			if !strings.Contains(line, "http_request") {
-				continue
-			}
+		trace := tempo.WaitForTrace(t, traceID, 60*time.Second, "controller", "scraper")
 
-			var logEntry map[string]interface{}
-			if err := json.Unmarshal([]byte(line), &logEntry); err != nil {
-				continue // Skip non-JSON lines
-			}
-
-			// Check if this is an HTTP request log with trace_id
-			if logEntry["msg"] == "http_request" {
-				traceID, ok := logEntry["trace_id"].(string)
-				if ok && traceID != "" && len(traceID) > 10 {
-					t.Logf("✓ Found trace_id in logs: %s", traceID)
-					foundTraceID = true
-
-					// Also verify span_id is present
-					spanID, ok := logEntry["span_id"].(string)
-					if !ok || spanID == "" {
-						t.Errorf("Found trace_id but span_id is empty or missing")
-					} else {
-						t.Logf("✓ Found span_id in logs: %s", spanID)
-					}
-					break
-				}
-			}
+		chain := []SpanMatcher{
+			{Service: "controller", Kind: "SPAN_KIND_SERVER"},
+			{Service: "scraper", Kind: "SPAN_KIND_SERVER"},
 		}
-
-		if !foundTraceID {
-			t.Error("No HTTP request logs with valid trace_id found in controller logs")
-			t.Log("Sample logs:")
-			for i, line := range logs {
-				if i >= 5 {
-					break
-				}
-				t.Logf("  %s", line)
-			}
+		// A failed scrape (e.g. no Ollama in CI) never reaches the model, so only
+		// a successful one is expected to carry the Ollama hop
+		if resp.StatusCode == http.StatusCreated {
+			chain = append(chain, SpanMatcher{Service: "scraper", Kind: "SPAN_KIND_CLIENT", Match: isOllamaSpan})
+		} else {
+			t.Log("Scrape failed; skipping the scraper -> Ollama span assertion")
 		}
+		AssertSpanChain(t, trace, rootSpanID, chain...)
 	})
 
-	t.Run("ScraperHTTPRequestHasTraceID", func(t *testing.T) {
-		// Make a direct request to scraper
-		requestBody := map[string]string{
-			"url": "https://example.com",
-		}
-		bodyBytes, err := json.Marshal(requestBody)
-		if err != nil {
-			t.Fatalf("Failed to marshal request body: %v", err)
-		}
+	t.Run("ScraperServerSpanContinuesTrace", func(t *testing.T) {
+		traceparent, traceID, rootSpanID := NewTraceparent()
 
-		resp, err := http.Post("http://localhost:9081/api/score",
-			"application/json",
-			bytes.NewBuffer(bodyBytes))
-		if err != nil {
-			t.Fatalf("Failed to make score request: %v", err)
-		}
-		defer resp.Body.Close()
-
-		// Give logs time to flush
-		time.Sleep(500 * time.Millisecond)
-
-		// Check scraper logs for trace_id
-		logs := getContainerLogs(t, "docutag-scraper", 50)
-
-		foundTraceID := false
-		for _, line := range logs {
-			if !strings.Contains(line, "http_request") {
-				continue
-			}
-
-			var logEntry map[string]interface{}
-			if err := json.Unmarshal([]byte(line), &logEntry); err != nil {
-				continue
-			}
+		resp := postWithTraceparent(t, "http://localhost:9081/api/score", traceparent,
+			map[string]interface{}{"url": "https://example.com"})
+		resp.Body.Close()
 
-			if logEntry["msg"] == "http_request" {
-				traceID, ok := logEntry["trace_id"].(string)
-				if ok && traceID != "" && len(traceID) > 10 {
-					t.Logf("✓ Found trace_id in scraper logs: %s", traceID)
-					foundTraceID = true
+		trace := tempo.WaitForTrace(t, traceID, 60*time.Second, "scraper")
 
-					spanID, ok := logEntry["span_id"].(string)
-					if !ok || spanID == "" {
-						t.Errorf("Found trace_id but span_id is empty or missing")
-					} else {
-						t.Logf("✓ Found span_id in scraper logs: %s", spanID)
-					}
-					break
-				}
-			}
-		}
-
-		if !foundTraceID {
-			t.Error("No HTTP request logs with valid trace_id found in scraper logs")
-		}
+		AssertSpanChain(t, trace, rootSpanID,
+			SpanMatcher{Service: "scraper", Kind: "SPAN_KIND_SERVER"},
+		)
 	})
 
-	t.Run("TextAnalyzerHTTPRequestHasTraceID", func(t *testing.T) {
-		// Make a direct request to textanalyzer
-		requestBody := map[string]interface{}{
-			"text": "This is a test document for trace propagation testing.",
-		}
-		bodyBytes, err := json.Marshal(requestBody)
-		if err != nil {
-			t.Fatalf("Failed to marshal request body: %v", err)
-		}
+	t.Run("TextAnalyzerServerSpanContinuesTrace", func(t *testing.T) {
+		traceparent, traceID, rootSpanID := NewTraceparent()
 
-		resp, err := http.Post("http://localhost:9082/api/analyze",
-			"application/json",
-			bytes.NewBuffer(bodyBytes))
-		if err != nil {
-			t.Fatalf("Failed to make analyze request: %v", err)
-		}
-		defer resp.Body.Close()
+		resp := postWithTraceparent(t, "http://localhost:9082/api/analyze", traceparent,
+			map[string]interface{}{"text": "This is a test document for trace propagation testing."})
+		resp.Body.Close()
 
-		// Give logs time to flush
-		time.Sleep(500 * time.Millisecond)
+		trace := tempo.WaitForTrace(t, traceID, 60*time.Second, "textanalyzer")
 
-		// Check textanalyzer logs for trace_id
-		logs := getContainerLogs(t, "docutag-textanalyzer", 50)
+		AssertSpanChain(t, trace, rootSpanID,
+			SpanMatcher{Service: "textanalyzer", Kind: "SPAN_KIND_SERVER"},
+		)
+	})
+}
 
-		foundTraceID := false
-		for _, line := range logs {
-			if !strings.Contains(line, "http_request") {
-				continue
-			}
+// postWithTraceparent POSTs a JSON body with the given W3C traceparent header
+func postWithTraceparent(t *testing.T, url, traceparent string, body interface{}) *http.Response {
+	t.Helper()
 
-			var logEntry map[string]interface{}
-			if err := json.Unmarshal([]byte(line), &logEntry); err != nil {
-				continue
-			}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to marshal request body: %v", err)
+	}
 
-			if logEntry["msg"] == "http_request" {
-				traceID, ok := logEntry["trace_id"].(string)
-				if ok && traceID != "" && len(traceID) > 10 {
-					t.Logf("✓ Found trace_id in textanalyzer logs: %s", traceID)
-					foundTraceID = true
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", traceparent)
 
-					spanID, ok := logEntry["span_id"].(string)
-					if !ok || spanID == "" {
-						t.Errorf("Found trace_id but span_id is empty or missing")
-					} else {
-						t.Logf("✓ Found span_id in textanalyzer logs: %s", spanID)
-					}
-					break
-				}
-			}
-		}
+	client := &http.Client{Timeout: 120 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request to %s failed: %v", url, err)
+	}
+	return resp
+}
 
-		if !foundTraceID {
-			t.Error("No HTTP request logs with valid trace_id found in textanalyzer logs")
+// isOllamaSpan reports whether a client span is a call to the Ollama API
+func isOllamaSpan(span TraceSpan) bool {
+	for _, key := range []string{"http.url", "url.full", "http.target", "url.path"} {
+		value := span.Attributes[key]
+		if strings.Contains(value, "/api/generate") || strings.Contains(value, "/api/chat") {
+			return true
 		}
-	})
+	}
+	return strings.Contains(strings.ToLower(span.Name), "ollama")
 }
 
 // waitForService waits for a service health endpoint to respond
@@ -237,27 +143,3 @@ func waitForService(t *testing.T, url, name string, timeout time.Duration) {
 
 	t.Fatalf("Service %s did not become ready within %v", name, timeout)
 }
-
-// getContainerLogs retrieves the last N lines of logs from a Docker container
-func getContainerLogs(t *testing.T, containerName string, lines int) []string {
-	t.Helper()
-
-	cmd := exec.Command("docker", "logs", "--tail", fmt.Sprintf("%d", lines), containerName)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		t.Logf("Warning: Failed to get container logs: %v", err)
-		return []string{}
-	}
-
-	// Split output into lines and filter out empty lines
-	logLines := strings.Split(string(output), "\n")
-	var result []string
-	for _, line := range logLines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed != "" {
-			result = append(result, trimmed)
-		}
-	}
-
-	return result
-}